@@ -0,0 +1,420 @@
+package q
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+)
+
+// OpType is the type of an operation.
+type OpType string
+
+const (
+	OpNew         OpType = "New"
+	OpApply       OpType = "Apply"
+	OpApplyAt     OpType = "ApplyAt"
+	OpG           OpType = "G"
+	OpU           OpType = "U"
+	OpI           OpType = "I"
+	OpX           OpType = "X"
+	OpY           OpType = "Y"
+	OpZ           OpType = "Z"
+	OpH           OpType = "H"
+	OpS           OpType = "S"
+	OpT           OpType = "T"
+	OpR           OpType = "R"
+	OpRX          OpType = "RX"
+	OpRY          OpType = "RY"
+	OpRZ          OpType = "RZ"
+	OpControlled  OpType = "Controlled"
+	OpControlledU OpType = "ControlledU"
+	OpControlledH OpType = "ControlledH"
+	OpControlledX OpType = "ControlledX"
+	OpControlledZ OpType = "ControlledZ"
+	OpControlledR OpType = "ControlledR"
+	OpSwap        OpType = "Swap"
+	OpMeasure     OpType = "Measure"
+	OpReset       OpType = "Reset"
+	OpBarrier     OpType = "Barrier"
+)
+
+// Condition is a classical condition of an operation.
+// The operation is applied if the classical bits equal Value,
+// where Clbit[i] is the i-th bit of Value.
+type Condition struct {
+	Clbit []int
+	Value int
+}
+
+// Op is an operation in a circuit.
+type Op struct {
+	Type    OpType
+	Control []Qubit
+	Target  []Qubit
+	Params  []float64
+	Matrix  *matrix.Matrix // for Apply, ApplyAt, G and Controlled
+	State   []complex128   // for New
+	Clbit   []int          // for Measure
+	Cond    *Condition
+}
+
+// Qubits returns the control and target qubits of op.
+func (op Op) Qubits() []Qubit {
+	qb := make([]Qubit, 0, len(op.Control)+len(op.Target))
+	qb = append(qb, op.Control...)
+	return append(qb, op.Target...)
+}
+
+// IsUnitary returns true if op is a unitary operation.
+func (op Op) IsUnitary() bool {
+	switch op.Type {
+	case OpNew, OpMeasure, OpReset:
+		return false
+	}
+
+	return true
+}
+
+// Inverse returns the inverse of op.
+// It returns false if op is not a unitary operation.
+func (op Op) Inverse() (Op, bool) {
+	if !op.IsUnitary() {
+		return Op{}, false
+	}
+
+	inv := op
+	switch op.Type {
+	case OpApply, OpApplyAt, OpG, OpControlled:
+		inv.Matrix = op.Matrix.Dagger()
+	case OpU, OpControlledU:
+		inv.Params = []float64{-op.Params[0], -op.Params[2], -op.Params[1]}
+	case OpS:
+		inv.Type, inv.Params = OpR, []float64{-math.Pi / 2}
+	case OpT:
+		inv.Type, inv.Params = OpR, []float64{-math.Pi / 4}
+	case OpR, OpRX, OpRY, OpRZ, OpControlledR:
+		inv.Params = []float64{-op.Params[0]}
+	}
+
+	return inv, true
+}
+
+// String returns the string representation of op.
+func (op Op) String() string {
+	var sb strings.Builder
+	sb.WriteString(string(op.Type))
+
+	if len(op.Params) > 0 {
+		p := make([]string, len(op.Params))
+		for i := range op.Params {
+			p[i] = fmt.Sprintf("%.4f", op.Params[i])
+		}
+
+		sb.WriteString("(" + strings.Join(p, ", ") + ")")
+	}
+
+	if len(op.Control) > 0 {
+		sb.WriteString(fmt.Sprintf(" %v", Index(op.Control...)))
+	}
+
+	if len(op.Target) > 0 {
+		sb.WriteString(fmt.Sprintf(" %v", Index(op.Target...)))
+	}
+
+	if len(op.Clbit) > 0 {
+		sb.WriteString(fmt.Sprintf(" -> %v", op.Clbit))
+	}
+
+	if op.Cond != nil {
+		sb.WriteString(fmt.Sprintf(" if %v == %d", op.Cond.Clbit, op.Cond.Value))
+	}
+
+	return sb.String()
+}
+
+// Circuit is an ordered list of operations.
+type Circuit struct {
+	NumQubits int
+	NumClbits int
+	Ops       []Op
+}
+
+// NewCircuit returns a new empty circuit.
+func NewCircuit() *Circuit {
+	return &Circuit{}
+}
+
+// Add appends operations to c.
+func (c *Circuit) Add(op ...Op) *Circuit {
+	for _, o := range op {
+		for _, qb := range o.Qubits() {
+			c.NumQubits = max(c.NumQubits, qb.Index()+1)
+		}
+
+		for _, b := range o.Clbit {
+			c.NumClbits = max(c.NumClbits, b+1)
+		}
+
+		if o.Cond != nil {
+			for _, b := range o.Cond.Clbit {
+				c.NumClbits = max(c.NumClbits, b+1)
+			}
+		}
+
+		c.Ops = append(c.Ops, o)
+	}
+
+	return c
+}
+
+// Len returns the number of operations in c.
+func (c *Circuit) Len() int {
+	return len(c.Ops)
+}
+
+// Clone returns a copy of c.
+func (c *Circuit) Clone() *Circuit {
+	ops := make([]Op, len(c.Ops))
+	copy(ops, c.Ops)
+
+	return &Circuit{
+		NumQubits: c.NumQubits,
+		NumClbits: c.NumClbits,
+		Ops:       ops,
+	}
+}
+
+// Inverse returns the inverse of c.
+// It returns an error if c contains a non-unitary operation.
+func (c *Circuit) Inverse() (*Circuit, error) {
+	out := &Circuit{
+		NumQubits: c.NumQubits,
+		NumClbits: c.NumClbits,
+		Ops:       make([]Op, 0, len(c.Ops)),
+	}
+
+	for i := len(c.Ops) - 1; i >= 0; i-- {
+		inv, ok := c.Ops[i].Inverse()
+		if !ok {
+			return nil, fmt.Errorf("operation %d is not unitary: %v", i, c.Ops[i])
+		}
+
+		out.Ops = append(out.Ops, inv)
+	}
+
+	return out, nil
+}
+
+// String returns the string representation of c.
+func (c *Circuit) String() string {
+	list := make([]string, len(c.Ops))
+	for i := range c.Ops {
+		list[i] = c.Ops[i].String()
+	}
+
+	return strings.Join(list, "\n")
+}
+
+// Record starts recording the operations applied to q into c.
+// If c is nil, it stops recording.
+func (q *Q) Record(c *Circuit) *Q {
+	q.rec, q.clbit = c, nil
+	return q
+}
+
+// Circuit returns the circuit q records into.
+// It returns nil if q is not recording.
+func (q *Q) Circuit() *Circuit {
+	return q.rec
+}
+
+// Run applies the operations of c to q and returns the classical bits.
+// Qubits referenced by c that do not exist in q are allocated in the zero state.
+// The qubits of New operations are appended to q, so q must not have more qubits
+// than when c was recorded. For example, run c on a new Q.
+func (q *Q) Run(c *Circuit) []int {
+	return run(q, c)
+}
+
+// simulator is a simulator that runs circuits.
+type simulator interface {
+	New(v ...complex128) Qubit
+	alloc(n int)
+	apply(op Op, bits []int)
+}
+
+// run applies the operations of c to s and returns the classical bits.
+func run(s simulator, c *Circuit) []int {
+	bits := make([]int, c.NumClbits)
+	for _, op := range c.Ops {
+		if op.Cond != nil && !op.Cond.Eval(bits) {
+			continue
+		}
+
+		if op.Type == OpNew {
+			s.alloc(op.Target[0].Index())
+			s.New(op.State...)
+			continue
+		}
+
+		for _, qb := range op.Qubits() {
+			s.alloc(qb.Index() + 1)
+		}
+
+		if op.Type == OpApply {
+			s.alloc(number.Log2(op.Matrix.Rows))
+		}
+
+		s.apply(op, bits)
+	}
+
+	s.alloc(c.NumQubits)
+	return bits
+}
+
+// Eval returns true if the classical bits satisfy c.
+func (c *Condition) Eval(bits []int) bool {
+	var v int
+	for i, b := range c.Clbit {
+		v |= bits[b] << i
+	}
+
+	return v == c.Value
+}
+
+// alloc appends qubits in the zero state until q has n qubits.
+func (q *Q) alloc(n int) {
+	for q.NumQubits() < n {
+		q.Zero()
+	}
+}
+
+// apply applies op to q and stores measurement results in bits.
+func (q *Q) apply(op Op, bits []int) {
+	switch op.Type {
+	case OpApply:
+		q.Apply(op.Matrix)
+	case OpApplyAt:
+		q.ApplyAt(op.Matrix, op.Target...)
+	case OpG:
+		q.G(op.Matrix, op.Target...)
+	case OpU:
+		q.U(op.Params[0], op.Params[1], op.Params[2], op.Target...)
+	case OpI:
+		q.I(op.Target...)
+	case OpX:
+		q.X(op.Target...)
+	case OpY:
+		q.Y(op.Target...)
+	case OpZ:
+		q.Z(op.Target...)
+	case OpH:
+		q.H(op.Target...)
+	case OpS:
+		q.S(op.Target...)
+	case OpT:
+		q.T(op.Target...)
+	case OpR:
+		q.R(op.Params[0], op.Target...)
+	case OpRX:
+		q.RX(op.Params[0], op.Target...)
+	case OpRY:
+		q.RY(op.Params[0], op.Target...)
+	case OpRZ:
+		q.RZ(op.Params[0], op.Target...)
+	case OpControlled:
+		q.Controlled(op.Matrix, op.Control, op.Target)
+	case OpControlledU:
+		q.ControlledU(op.Params[0], op.Params[1], op.Params[2], op.Control, op.Target)
+	case OpControlledH:
+		q.ControlledH(op.Control, op.Target)
+	case OpControlledX:
+		q.ControlledX(op.Control, op.Target)
+	case OpControlledZ:
+		q.ControlledZ(op.Control, op.Target)
+	case OpControlledR:
+		q.ControlledR(op.Params[0], op.Control, op.Target)
+	case OpSwap:
+		q.Swap(op.Target[0], op.Target[1])
+	case OpReset:
+		q.Reset(op.Target...)
+	case OpMeasure:
+		for i, qb := range op.Target {
+			m := q.Measure(qb)
+			if i >= len(op.Clbit) {
+				continue
+			}
+
+			bits[op.Clbit[i]] = 0
+			if m.IsOne() {
+				bits[op.Clbit[i]] = 1
+			}
+		}
+	}
+}
+
+// record appends op to the circuit if q is recording.
+func (q *Q) record(op Op) {
+	if q.rec == nil {
+		return
+	}
+
+	op.Control = append([]Qubit(nil), op.Control...)
+	q.rec.Add(op)
+}
+
+// recordMeasure appends a measurement of qb to the circuit if q is recording.
+func (q *Q) recordMeasure(qb Qubit) {
+	if q.rec == nil {
+		return
+	}
+
+	if q.clbit == nil {
+		q.clbit = make(map[Qubit]int)
+	}
+
+	q.clbit[qb] = q.rec.NumClbits
+	q.rec.Add(Op{
+		Type:   OpMeasure,
+		Target: []Qubit{qb},
+		Clbit:  []int{q.rec.NumClbits},
+	})
+}
+
+// cond applies op if the outcomes of the last measurements of m equal value.
+// While recording, op is recorded with the condition on the classical bits of the measurements.
+// If a measurement of m is not recorded, op is recorded only if it is applied.
+func (q *Q) cond(m []Qubit, value int, op Op) {
+	var v int
+	clbit := make([]int, len(m))
+	recorded := q.rec != nil
+	for i, qb := range m {
+		v |= q.bits[qb] << i
+
+		c, ok := q.clbit[qb]
+		clbit[i], recorded = c, recorded && ok
+	}
+
+	if !recorded {
+		if v == value {
+			q.apply(op, nil)
+		}
+
+		return
+	}
+
+	op.Cond = &Condition{Clbit: clbit, Value: value}
+	q.record(op)
+	if v != value {
+		return
+	}
+
+	// apply op without recording it again.
+	rec := q.rec
+	q.rec = nil
+	q.apply(op, nil)
+	q.rec = rec
+}
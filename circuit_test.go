@@ -0,0 +1,329 @@
+package q_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q"
+	F "github.com/itsubaki/q/function"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleQ_Record() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	qsim.H(q0)
+	qsim.CNOT(q0, q1)
+	qsim.Measure(q0, q1)
+
+	fmt.Println(c)
+	fmt.Println(c.NumQubits, c.NumClbits)
+
+	// Output:
+	// New [0]
+	// New [1]
+	// H [0]
+	// ControlledX [0] [1]
+	// Measure [0] -> [0]
+	// Measure [1] -> [1]
+	// 2 2
+}
+
+func ExampleQ_Run() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	qsim.H(q0)
+	qsim.CNOT(q0, q1)
+
+	out := q.New()
+	out.Run(c)
+
+	for _, s := range out.State() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [00] ( 0.7071 0.0000i): 0.5000
+	// [11] ( 0.7071 0.0000i): 0.5000
+}
+
+func ExampleQ_Run_cond() {
+	c := q.NewCircuit().Add(
+		q.Op{Type: q.OpU, Target: []q.Qubit{0}, Params: []float64{2 * math.Atan(2), 0, 0}},
+		q.Op{Type: q.OpH, Target: []q.Qubit{1}},
+		q.Op{Type: q.OpControlledX, Control: []q.Qubit{1}, Target: []q.Qubit{2}},
+		q.Op{Type: q.OpControlledX, Control: []q.Qubit{0}, Target: []q.Qubit{1}},
+		q.Op{Type: q.OpH, Target: []q.Qubit{0}},
+		q.Op{Type: q.OpMeasure, Target: []q.Qubit{0, 1}, Clbit: []int{0, 1}},
+		q.Op{Type: q.OpX, Target: []q.Qubit{2}, Cond: &q.Condition{Clbit: []int{1}, Value: 1}},
+		q.Op{Type: q.OpZ, Target: []q.Qubit{2}, Cond: &q.Condition{Clbit: []int{0}, Value: 1}},
+	)
+
+	qsim := q.New()
+	qsim.Run(c)
+
+	for _, s := range qsim.State(q.Qubit(2)) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [0] ( 0.4472 0.0000i): 0.2000
+	// [1] ( 0.8944 0.0000i): 0.8000
+}
+
+func ExampleQ_IfX() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	psi := qsim.New(1, 2)
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+
+	qsim.H(q0)
+	qsim.CNOT(q0, q1)
+	qsim.CNOT(psi, q0)
+	qsim.H(psi)
+	qsim.Measure(psi, q0)
+	qsim.IfX([]q.Qubit{q0}, 1, q1)
+	qsim.IfZ([]q.Qubit{psi}, 1, q1)
+
+	fmt.Println(c.Ops[len(c.Ops)-2])
+	fmt.Println(c.Ops[len(c.Ops)-1])
+
+	out := q.New()
+	out.Run(c)
+
+	for _, s := range out.State(q1) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// X [2] if [1] == 1
+	// Z [2] if [0] == 1
+	// [0] ( 0.4472 0.0000i): 0.2000
+	// [1] ( 0.8944 0.0000i): 0.8000
+}
+
+func ExampleCircuit_Inverse() {
+	c := q.NewCircuit().Add(
+		q.Op{Type: q.OpH, Target: []q.Qubit{0}},
+		q.Op{Type: q.OpT, Target: []q.Qubit{0}},
+		q.Op{Type: q.OpControlledX, Control: []q.Qubit{0}, Target: []q.Qubit{1}},
+	)
+
+	inv, err := c.Inverse()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(inv)
+
+	// Output:
+	// ControlledX [0] [1]
+	// R(-0.7854) [0]
+	// H [0]
+}
+
+func ExampleCircuit_Inverse_measure() {
+	c := q.NewCircuit().Add(
+		q.Op{Type: q.OpH, Target: []q.Qubit{0}},
+		q.Op{Type: q.OpMeasure, Target: []q.Qubit{0}, Clbit: []int{0}},
+	)
+
+	_, err := c.Inverse()
+	fmt.Println(err)
+
+	// Output:
+	// operation 1 is not unitary: Measure [0] -> [0]
+}
+
+func TestRecord(t *testing.T) {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	q0 := qsim.Zero()
+	q1 := qsim.One()
+	q2 := qsim.New(1, 2)
+	q3 := qsim.Zero()
+
+	qsim.H(q0, q1)
+	qsim.U(1.0, 2.0, 3.0, q2)
+	qsim.RX(0.1, q0).RY(0.2, q1).RZ(0.3, q2)
+	qsim.S(q3).T(q3).R(0.4, q3)
+	qsim.Y(q0).Z(q1).I(q2)
+	qsim.G(gate.H(), q3)
+	qsim.CU(1.0, 2.0, 3.0, q0, q3)
+	qsim.ControlledH([]q.Qubit{q1}, []q.Qubit{q2})
+	qsim.C(gate.RY(0.5), q2, q0)
+	qsim.CCZ(q0, q1, q3)
+	qsim.Apply(gate.Swap(4, 0, 3))
+	qsim.ApplyAt(gate.CNOT(2, 0, 1), q3, q1)
+	F.QFT(qsim, q0, q1, q2, q3)
+	F.Swap(qsim, q0, q1, q2, q3)
+
+	if c.NumQubits != 4 {
+		t.Errorf("got=%v, want=%v", c.NumQubits, 4)
+	}
+
+	got := q.New()
+	got.Run(c)
+	if !qubit.Equal(got.State(), qsim.State()) {
+		t.Errorf("got=%v, want=%v", got.State(), qsim.State())
+	}
+
+	inv, err := c.Clone().Inverse()
+	if err == nil {
+		t.Errorf("got=%v", inv)
+	}
+}
+
+func TestQ_If(t *testing.T) {
+	cases := []struct {
+		value int
+		bits  int
+		want  int
+	}{
+		{0, 0, 1},
+		{0, 1, 0},
+		{1, 1, 1},
+		{1, 2, 0},
+		{2, 2, 1},
+		{2, 3, 0},
+		{3, 3, 1},
+		{3, 0, 0},
+	}
+
+	for _, c := range cases {
+		circuit := q.NewCircuit()
+
+		qsim := q.New()
+		qsim.Record(circuit)
+
+		// the outcome of m[i] is the i-th bit of c.bits.
+		m := qsim.Zeros(2)
+		target := qsim.Zero()
+		for i := range m {
+			if c.bits>>i&1 == 1 {
+				qsim.X(m[i])
+			}
+		}
+
+		qsim.Measure(m...)
+		qsim.If(m, c.value, gate.X(), target)
+
+		if got := qsim.State(target)[0].BinaryString()[0]; got != fmt.Sprint(c.want) {
+			t.Errorf("value=%v, bits=%v, got=%v, want=%v", c.value, c.bits, got, c.want)
+		}
+
+		cond := circuit.Ops[len(circuit.Ops)-1].Cond
+		if cond == nil || cond.Value != c.value || len(cond.Clbit) != 2 || cond.Clbit[0] != 0 || cond.Clbit[1] != 1 {
+			t.Errorf("cond=%v", cond)
+		}
+
+		out := q.New()
+		out.Run(circuit)
+		if !qubit.Equal(out.State(), qsim.State()) {
+			t.Errorf("got=%v, want=%v", out.State(), qsim.State())
+		}
+	}
+}
+
+func TestQ_If_teleportation(t *testing.T) {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	psi := qsim.New(1, 2)
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+
+	qsim.H(q0)
+	qsim.CNOT(q0, q1)
+	qsim.CNOT(psi, q0)
+	qsim.H(psi)
+	qsim.Measure(psi, q0)
+	qsim.IfX([]q.Qubit{q0}, 1, q1)
+	qsim.IfZ([]q.Qubit{psi}, 1, q1)
+
+	// the replay teleports the state for any measurement outcomes.
+	for seed := range 20 {
+		out := q.New()
+		out.SetRand(rand.Const(uint64(seed)))
+		out.Run(c)
+
+		got := out.State(q1)
+		if len(got) != 2 || math.Abs(got[0].Probability()-0.2) > 1e-8 || math.Abs(got[1].Probability()-0.8) > 1e-8 {
+			t.Errorf("seed=%v, got=%v", seed, got)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qb := qsim.Zeros(3)
+	qsim.H(qb...)
+
+	want := qsim.Clone()
+	qsim.Record(c)
+	qsim.U(1.0, 2.0, 3.0, qb[0])
+	qsim.S(qb[1]).T(qb[2])
+	qsim.CU(0.1, 0.2, 0.3, qb[0], qb[1])
+	qsim.CR(0.4, qb[1], qb[2])
+	qsim.C(gate.U(0.5, 0.6, 0.7), qb[2], qb[0])
+	qsim.RX(0.8, qb[0]).RY(0.9, qb[1]).RZ(1.0, qb[2])
+	qsim.ApplyAt(gate.CR(0.5, 2, 0, 1), qb[2], qb[0])
+	F.QFT(qsim, qb...)
+	qsim.Record(nil)
+
+	inv, err := c.Inverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if qsim.Run(inv); !qubit.Equal(qsim.State(), want.State()) {
+		t.Errorf("got=%v, want=%v", qsim.State(), want.State())
+	}
+}
+
+func TestReset(t *testing.T) {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.SetRand(rand.Const())
+	qsim.Record(c)
+
+	q0 := qsim.Zero()
+	qsim.X(q0)
+	qsim.Reset(q0)
+
+	if c.Len() != 3 {
+		t.Errorf("got=%v", c)
+	}
+
+	got := q.New()
+	got.Run(c)
+	if !qubit.Equal(got.State(), qsim.State()) {
+		t.Errorf("got=%v, want=%v", got.State(), qsim.State())
+	}
+}
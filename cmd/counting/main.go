@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"runtime"
 	"runtime/pprof"
 
 	"github.com/itsubaki/q"
@@ -75,13 +76,15 @@ func main() {
 	defer pprof.StopCPUProfile()
 
 	// flags
-	var t, top int
+	var t, top, workers int
 	flag.IntVar(&t, "t", 7, "precision bits")
 	flag.IntVar(&top, "top", 8, "top results")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "number of goroutines")
 	flag.Parse()
 
 	// quantum simulator
 	qsim := q.New()
+	qsim.SetWorkers(workers)
 
 	// initialize
 	c := qsim.Zeros(t) // for phase estimation
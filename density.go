@@ -0,0 +1,660 @@
+package q
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/channel"
+	"github.com/itsubaki/q/quantum/density"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/noise"
+	"github.com/itsubaki/q/quantum/observable"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+// Density is a quantum computing simulator backed by a density matrix.
+// It has the same method names as Q and applies noise channels inline.
+type Density struct {
+	rho   *density.DensityMatrix
+	rand  func() float64
+	noise *noise.Model
+	rec   *Circuit
+}
+
+// NewDensity returns a new density matrix simulator.
+func NewDensity() *Density {
+	return &Density{
+		rand: rand.Float64,
+	}
+}
+
+// SetRand sets the random number generator.
+func (d *Density) SetRand(rand func() float64) {
+	d.rand = rand
+}
+
+// SetNoise sets the noise model applied on each operation.
+// The idle noise is applied to the other qubits once per operation as noise.Model.SetIdle.
+// If m is nil, no noise is applied.
+func (d *Density) SetNoise(m *noise.Model) {
+	d.noise = m
+}
+
+// Record starts recording the operations applied to d into c.
+// The noise channels are not recorded. If c is nil, it stops recording.
+func (d *Density) Record(c *Circuit) *Density {
+	d.rec = c
+	return d
+}
+
+// New appends a new qubit in the pure state and returns its index.
+func (d *Density) New(v ...complex128) Qubit {
+	n := d.NumQubits()
+
+	rho := density.New(qubit.New(vector.New(v...)))
+	if n == 0 {
+		d.rho = rho
+	} else {
+		d.rho = d.rho.TensorProduct(rho)
+	}
+
+	if d.rec != nil {
+		target := make([]Qubit, d.NumQubits()-n)
+		for i := range target {
+			target[i] = Qubit(n + i)
+		}
+
+		d.record(Op{Type: OpNew, Target: target, State: v})
+	}
+
+	return Qubit(d.NumQubits() - 1)
+}
+
+// Zero returns a qubit in the zero state.
+func (d *Density) Zero() Qubit {
+	return d.New(1, 0)
+}
+
+// One returns a qubit in the one state.
+func (d *Density) One() Qubit {
+	return d.New(0, 1)
+}
+
+// Zeros returns n qubits in the zero state.
+func (d *Density) Zeros(n int) []Qubit {
+	qb := make([]Qubit, n)
+	for i := range n {
+		qb[i] = d.Zero()
+	}
+
+	return qb
+}
+
+// Ones returns n qubits in the one state.
+func (d *Density) Ones(n int) []Qubit {
+	qb := make([]Qubit, n)
+	for i := range n {
+		qb[i] = d.One()
+	}
+
+	return qb
+}
+
+// NumQubits returns the number of qubits.
+func (d *Density) NumQubits() int {
+	if d.rho == nil {
+		return 0
+	}
+
+	return d.rho.NumQubits()
+}
+
+// Probability returns the probabilities of the computational basis states.
+func (d *Density) Probability() []float64 {
+	if d.rho == nil {
+		return []float64{}
+	}
+
+	p := make([]float64, 1<<d.NumQubits())
+	for i := range p {
+		p[i] = real(d.rho.At(i, i))
+	}
+
+	return p
+}
+
+// Reset sets the given qubits to the zero state.
+// Unlike Q, it does not measure the qubits and keeps the other qubits mixed.
+func (d *Density) Reset(qb ...Qubit) {
+	k0 := matrix.New(
+		[]complex128{1, 0},
+		[]complex128{0, 0},
+	)
+
+	k1 := matrix.New(
+		[]complex128{0, 1},
+		[]complex128{0, 0},
+	)
+
+	reset := channel.New(k0, k1)
+	for i := range qb {
+		d.record(Op{Type: OpReset, Target: []Qubit{qb[i]}})
+		d.rho = d.rho.ApplyChannelAt(reset, qb[i].Index())
+		d.noisy(OpReset, qb[i:i+1])
+	}
+}
+
+// Apply applies a list of gates to the qubits.
+func (d *Density) Apply(g ...*matrix.Matrix) *Density {
+	qb := make([]Qubit, d.NumQubits())
+	for i := range qb {
+		qb[i] = Qubit(i)
+	}
+
+	for i := range g {
+		d.record(Op{Type: OpApply, Matrix: g[i]})
+		d.rho = d.rho.Apply(g[i])
+		d.noisy(OpApply, qb)
+	}
+
+	return d
+}
+
+// ApplyAt applies a 2^k x 2^k gate to the k qubits.
+// The first qubit corresponds to the most significant bit of the gate.
+func (d *Density) ApplyAt(g *matrix.Matrix, qb ...Qubit) *Density {
+	d.record(Op{Type: OpApplyAt, Target: append([]Qubit(nil), qb...), Matrix: g})
+	d.rho = d.rho.ApplyAt(g, Index(qb...)...)
+	d.noisy(OpApplyAt, qb)
+	return d
+}
+
+// G applies a gate.
+func (d *Density) G(g *matrix.Matrix, qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpG, Matrix: g}, g, qb)
+}
+
+// U applies the U gate.
+func (d *Density) U(theta, phi, lambda float64, qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpU, Params: []float64{theta, phi, lambda}}, gate.U(theta, phi, lambda), qb)
+}
+
+// I applies the I gate.
+func (d *Density) I(qb ...Qubit) *Density {
+	for i := range qb {
+		d.record(Op{Type: OpI, Target: []Qubit{qb[i]}})
+		d.noisy(OpI, qb[i:i+1])
+	}
+
+	return d
+}
+
+// X applies the X gate.
+func (d *Density) X(qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpX}, gate.X(), qb)
+}
+
+// Y applies the Y gate.
+func (d *Density) Y(qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpY}, gate.Y(), qb)
+}
+
+// Z applies the Z gate.
+func (d *Density) Z(qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpZ}, gate.Z(), qb)
+}
+
+// H applies the H gate.
+func (d *Density) H(qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpH}, gate.H(), qb)
+}
+
+// S applies the S gate.
+func (d *Density) S(qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpS}, gate.S(), qb)
+}
+
+// T applies the T gate.
+func (d *Density) T(qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpT}, gate.T(), qb)
+}
+
+// R applies the R gate with theta.
+func (d *Density) R(theta float64, qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpR, Params: []float64{theta}}, gate.R(theta), qb)
+}
+
+// RX applies the RX gate with theta.
+func (d *Density) RX(theta float64, qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpRX, Params: []float64{theta}}, gate.RX(theta), qb)
+}
+
+// RY applies the RY gate with theta.
+func (d *Density) RY(theta float64, qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpRY, Params: []float64{theta}}, gate.RY(theta), qb)
+}
+
+// RZ applies the RZ gate with theta.
+func (d *Density) RZ(theta float64, qb ...Qubit) *Density {
+	return d.gate(Op{Type: OpRZ, Params: []float64{theta}}, gate.RZ(theta), qb)
+}
+
+// C applies a controlled operation with g.
+func (d *Density) C(g *matrix.Matrix, control, target Qubit) *Density {
+	return d.Controlled(g, []Qubit{control}, []Qubit{target})
+}
+
+// CU applies a controlled unitary operation.
+func (d *Density) CU(theta, phi, lambda float64, control, target Qubit) *Density {
+	return d.ControlledU(theta, phi, lambda, []Qubit{control}, []Qubit{target})
+}
+
+// CX applies the CNOT gate.
+func (d *Density) CX(control, target Qubit) *Density {
+	return d.ControlledNot([]Qubit{control}, []Qubit{target})
+}
+
+// CNOT applies the CNOT gate.
+func (d *Density) CNOT(control, target Qubit) *Density {
+	return d.ControlledNot([]Qubit{control}, []Qubit{target})
+}
+
+// CCNOT applies the CCNOT gate.
+func (d *Density) CCNOT(control0, control1, target Qubit) *Density {
+	return d.ControlledNot([]Qubit{control0, control1}, []Qubit{target})
+}
+
+// CZ applies the controlled-Z gate.
+func (d *Density) CZ(control, target Qubit) *Density {
+	return d.ControlledZ([]Qubit{control}, []Qubit{target})
+}
+
+// CR applies the controlled-R gate.
+func (d *Density) CR(theta float64, control, target Qubit) *Density {
+	return d.ControlledR(theta, []Qubit{control}, []Qubit{target})
+}
+
+// Controlled applies a controlled operation with g.
+func (d *Density) Controlled(g *matrix.Matrix, control, target []Qubit) *Density {
+	return d.controlled(Op{Type: OpControlled, Matrix: g}, g, control, target)
+}
+
+// ControlledU applies a controlled unitary operation.
+func (d *Density) ControlledU(theta, phi, lambda float64, control, target []Qubit) *Density {
+	return d.controlled(Op{Type: OpControlledU, Params: []float64{theta, phi, lambda}}, gate.U(theta, phi, lambda), control, target)
+}
+
+// ControlledH applies the controlled-Hadamard gate.
+func (d *Density) ControlledH(control, target []Qubit) *Density {
+	return d.controlled(Op{Type: OpControlledH}, gate.H(), control, target)
+}
+
+// ControlledX applies the CNOT gate.
+func (d *Density) ControlledX(control, target []Qubit) *Density {
+	return d.ControlledNot(control, target)
+}
+
+// ControlledNot applies the CNOT gate.
+func (d *Density) ControlledNot(control, target []Qubit) *Density {
+	return d.controlled(Op{Type: OpControlledX}, gate.X(), control, target)
+}
+
+// ControlledZ applies the controlled-Z gate.
+func (d *Density) ControlledZ(control, target []Qubit) *Density {
+	return d.controlled(Op{Type: OpControlledZ}, gate.Z(), control, target)
+}
+
+// ControlledR applies the controlled-R gate.
+func (d *Density) ControlledR(theta float64, control, target []Qubit) *Density {
+	return d.controlled(Op{Type: OpControlledR, Params: []float64{theta}}, gate.R(theta), control, target)
+}
+
+// Swap applies the swap gate.
+func (d *Density) Swap(qb0, qb1 Qubit) *Density {
+	d.record(Op{Type: OpSwap, Target: []Qubit{qb0, qb1}})
+	d.rho = d.rho.ApplyAt(gate.Swap(2, 0, 1), qb0.Index(), qb1.Index())
+	d.noisy(OpSwap, []Qubit{qb0, qb1})
+	return d
+}
+
+// Depolarizing applies the depolarizing channel to the given qubits.
+// If no qubits are given, it applies the channel to all qubits.
+func (d *Density) Depolarizing(p float64, qb ...Qubit) *Density {
+	d.rho = d.rho.Depolarizing(p, Index(qb...)...)
+	return d
+}
+
+// Pauli applies the Pauli channel to the given qubits.
+// If no qubits are given, it applies the channel to all qubits.
+func (d *Density) Pauli(px, py, pz float64, qb ...Qubit) *Density {
+	d.rho = d.rho.Pauli(px, py, pz, Index(qb...)...)
+	return d
+}
+
+// BitFlip applies the bit flip channel to the given qubits.
+// If no qubits are given, it applies the channel to all qubits.
+func (d *Density) BitFlip(p float64, qb ...Qubit) *Density {
+	d.rho = d.rho.BitFlip(p, Index(qb...)...)
+	return d
+}
+
+// PhaseFlip applies the phase flip channel to the given qubits.
+// If no qubits are given, it applies the channel to all qubits.
+func (d *Density) PhaseFlip(p float64, qb ...Qubit) *Density {
+	d.rho = d.rho.PhaseFlip(p, Index(qb...)...)
+	return d
+}
+
+// BitPhaseFlip applies the bit-phase flip channel to the given qubits.
+// If no qubits are given, it applies the channel to all qubits.
+func (d *Density) BitPhaseFlip(p float64, qb ...Qubit) *Density {
+	d.rho = d.rho.BitPhaseFlip(p, Index(qb...)...)
+	return d
+}
+
+// AmplitudeDamping applies the amplitude damping channel to the given qubits.
+// If no qubits are given, it applies the channel to all qubits.
+func (d *Density) AmplitudeDamping(gamma float64, qb ...Qubit) *Density {
+	d.rho = d.rho.AmplitudeDamping(gamma, Index(qb...)...)
+	return d
+}
+
+// PhaseDamping applies the phase damping channel to the given qubits.
+// If no qubits are given, it applies the channel to all qubits.
+func (d *Density) PhaseDamping(gamma float64, qb ...Qubit) *Density {
+	d.rho = d.rho.PhaseDamping(gamma, Index(qb...)...)
+	return d
+}
+
+// ApplyChannel applies the quantum channels.
+func (d *Density) ApplyChannel(fn ...channel.ChannelFunc) *Density {
+	d.rho = d.rho.ApplyChannelFunc(fn...)
+	return d
+}
+
+// M returns the measured state of the given qubits.
+func (d *Density) M(qb ...Qubit) *qubit.Qubit {
+	return d.Measure(qb...)
+}
+
+// Measure returns the measured state of the given qubits.
+// The state collapses to the post-measurement state of the outcome.
+// The returned state has the readout errors of the noise model.
+// If no qubits are given, it measures all qubits.
+func (d *Density) Measure(qb ...Qubit) *qubit.Qubit {
+	if len(qb) < 1 {
+		qb = make([]Qubit, d.NumQubits())
+		for i := range qb {
+			qb[i] = Qubit(i)
+		}
+	}
+
+	bits := make([]int, len(qb))
+	for i := range qb {
+		d.recordMeasure(qb[i])
+
+		bits[i] = 1
+		p0, rho := d.rho.MeasureAt(projector(0), []int{qb[i].Index()})
+		if d.rand() < p0 {
+			bits[i] = 0
+		} else {
+			_, rho = d.rho.MeasureAt(projector(1), []int{qb[i].Index()})
+		}
+
+		d.rho = rho
+	}
+
+	if d.noise != nil {
+		bits = d.noise.Read(bits, Index(qb...), d.rand)
+	}
+
+	m := make([]*qubit.Qubit, len(qb))
+	for i, bit := range bits {
+		m[i] = qubit.Zero()
+		if bit == 1 {
+			m[i] = qubit.One()
+		}
+	}
+
+	return qubit.TensorProduct(m...)
+}
+
+// MeasureMixed measures the given qubits without reading the outcomes.
+// The state becomes the mixture of the post-measurement states weighted by their probabilities.
+// If no qubits are given, it measures all qubits.
+func (d *Density) MeasureMixed(qb ...Qubit) *Density {
+	if len(qb) < 1 {
+		qb = make([]Qubit, d.NumQubits())
+		for i := range qb {
+			qb[i] = Qubit(i)
+		}
+	}
+
+	measure := channel.New(projector(0), projector(1))
+	for i := range qb {
+		d.rho = d.rho.ApplyChannelAt(measure, qb[i].Index())
+	}
+
+	return d
+}
+
+// Sample returns the counts of shots drawn from the probability distribution of the given qubits.
+// If no qubits are given, it samples all qubits. The state of d is not changed.
+func (d *Density) Sample(shots int, qb ...Qubit) qubit.Counts {
+	p := d.Probability()
+
+	amp := make([]complex128, len(p))
+	for i := range p {
+		amp[i] = complex(math.Sqrt(max(p[i], 0)), 0)
+	}
+
+	s := qubit.New(vector.New(amp...))
+	s.SetRand(d.rand)
+	return s.Sample(shots, Index(qb...)...)
+}
+
+// Expect returns the expectation value of the observable.
+func (d *Density) Expect(observable *matrix.Matrix) float64 {
+	if d.rho == nil {
+		return 0
+	}
+
+	return d.rho.Expect(observable)
+}
+
+// ExpectPauli returns the expectation value of the Pauli string such as "XZI".
+// The i-th character of s acts on qb[i], or on the i-th qubit if no qubits are given.
+// It returns an error if s is not a valid Pauli string for the qubits.
+func (d *Density) ExpectPauli(s string, qb ...Qubit) (float64, error) {
+	n := d.NumQubits()
+	if len(qb) > 0 && len(qb) != len(s) {
+		return 0, fmt.Errorf("invalid number of qubits: %d for %q", len(qb), s)
+	}
+
+	if len(qb) == 0 && len(s) > n {
+		return 0, fmt.Errorf("invalid length of %q: %d qubits", s, n)
+	}
+
+	p := []byte(strings.Repeat("I", n))
+	seen := make(map[int]bool, len(s))
+	for i, c := range []byte(s) {
+		if !strings.ContainsRune("IXYZ", rune(c)) {
+			return 0, fmt.Errorf("invalid Pauli operator %q in %q", c, s)
+		}
+
+		k := i
+		if len(qb) > 0 {
+			k = qb[i].Index()
+		}
+
+		if k < 0 || k >= n {
+			return 0, fmt.Errorf("index %d out of range for %d qubits", k, n)
+		}
+
+		if seen[k] {
+			return 0, fmt.Errorf("duplicate index %d", k)
+		}
+
+		seen[k], p[k] = true, c
+	}
+
+	if d.rho == nil {
+		return 0, nil
+	}
+
+	return d.rho.Expect(observable.Pauli(string(p))), nil
+}
+
+// Run applies the operations of c to d and returns the classical bits.
+// Qubits referenced by c that do not exist in d are allocated in the zero state.
+func (d *Density) Run(c *Circuit) []int {
+	return run(d, c)
+}
+
+// Clone returns a copy of d.
+func (d *Density) Clone() *Density {
+	out := &Density{
+		rand:  d.rand,
+		noise: d.noise,
+	}
+
+	if d.rho != nil {
+		out.rho = d.rho.Clone()
+	}
+
+	return out
+}
+
+// DensityMatrix returns the internal density matrix.
+func (d *Density) DensityMatrix() *density.DensityMatrix {
+	return d.rho
+}
+
+// gate applies the single-qubit gate g to each of the qubits.
+func (d *Density) gate(op Op, g *matrix.Matrix, qb []Qubit) *Density {
+	for i := range qb {
+		op.Target = []Qubit{qb[i]}
+		d.record(op)
+		d.rho = d.rho.ApplyAt(g, qb[i].Index())
+		d.noisy(op.Type, op.Target)
+	}
+
+	return d
+}
+
+// controlled applies the single-qubit gate g controlled by the control qubits to each of the target qubits.
+func (d *Density) controlled(op Op, g *matrix.Matrix, control, target []Qubit) *Density {
+	c := make([]int, len(control))
+	for i := range c {
+		c[i] = i
+	}
+
+	u := gate.Controlled(g, len(control)+1, c, len(control))
+	for i := range target {
+		op.Control, op.Target = control, []Qubit{target[i]}
+		d.record(op)
+		d.rho = d.rho.ApplyAt(u, append(Index(control...), target[i].Index())...)
+		d.noisy(op.Type, op.Qubits())
+	}
+
+	return d
+}
+
+// projector returns the single-qubit projector onto the given outcome.
+func projector(outcome int) *matrix.Matrix {
+	p := matrix.Zero(2, 2)
+	p.Set(outcome, outcome, 1)
+	return p
+}
+
+// noisy applies the noise of the operation on the qubits and the noise of the idle qubits.
+// It is called once per operation, so the idle qubits have the idle noise once per operation.
+func (d *Density) noisy(t OpType, qb []Qubit) {
+	if d.noise == nil {
+		return
+	}
+
+	if ch := d.noise.Channel(string(t), Index(qb...)...); ch != nil {
+		d.rho = d.rho.ApplyChannelAt(ch, Index(qb...)...)
+	}
+
+	idle := d.noise.Idle()
+	if idle == nil {
+		return
+	}
+
+	busy := make(map[int]bool)
+	for _, q := range qb {
+		busy[q.Index()] = true
+	}
+
+	for i := range d.NumQubits() {
+		if busy[i] {
+			continue
+		}
+
+		d.rho = d.rho.ApplyChannelAt(idle, i)
+	}
+}
+
+// alloc appends qubits in the zero state until d has n qubits.
+func (d *Density) alloc(n int) {
+	for d.NumQubits() < n {
+		d.Zero()
+	}
+}
+
+// apply applies op to d and stores measurement results in bits.
+func (d *Density) apply(op Op, bits []int) {
+	switch op.Type {
+	case OpApply:
+		d.Apply(op.Matrix)
+	case OpReset:
+		d.Reset(op.Target...)
+	case OpMeasure:
+		for i, qb := range op.Target {
+			m := d.Measure(qb)
+			if i >= len(op.Clbit) {
+				continue
+			}
+
+			bits[op.Clbit[i]] = 0
+			if m.IsOne() {
+				bits[op.Clbit[i]] = 1
+			}
+		}
+	case OpBarrier:
+	default:
+		qb := unique(op.Qubits())
+		d.record(op)
+		d.rho = d.rho.ApplyAt(unitary([]Op{op}, qb), Index(qb...)...)
+		d.noisy(op.Type, op.Qubits())
+	}
+}
+
+// record appends op to the circuit if d is recording.
+func (d *Density) record(op Op) {
+	if d.rec == nil {
+		return
+	}
+
+	op.Control = append([]Qubit(nil), op.Control...)
+	d.rec.Add(op)
+}
+
+// recordMeasure appends a measurement of qb to the circuit if d is recording.
+func (d *Density) recordMeasure(qb Qubit) {
+	if d.rec == nil {
+		return
+	}
+
+	d.rec.Add(Op{
+		Type:   OpMeasure,
+		Target: []Qubit{qb},
+		Clbit:  []int{d.rec.NumClbits},
+	})
+}
@@ -0,0 +1,352 @@
+package q_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q"
+	F "github.com/itsubaki/q/function"
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/channel"
+	"github.com/itsubaki/q/quantum/density"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/noise"
+	"github.com/itsubaki/q/quantum/readout"
+)
+
+func ExampleDensity() {
+	qsim := q.NewDensity()
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+
+	qsim.H(q0).CNOT(q0, q1)
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("ZZ")))
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("XX")))
+
+	qsim.Depolarizing(0.3, q0)
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("ZZ")))
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("XX")))
+	fmt.Printf("%.4f\n", qsim.DensityMatrix().Purity())
+
+	// Output:
+	// 1.0000
+	// 1.0000
+	// 0.6000
+	// 0.6000
+	// 0.5200
+}
+
+func ExampleDensity_Measure() {
+	qsim := q.NewDensity()
+	qsim.SetRand(rand.Const(1))
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+
+	qsim.H(q0).CNOT(q0, q1)
+	m0 := qsim.Measure(q0)
+	m1 := qsim.Measure(q1)
+
+	fmt.Println(m0.IsZero() == m1.IsZero())
+	fmt.Println(qsim.DensityMatrix().IsPure())
+
+	// Output:
+	// true
+	// true
+}
+
+func ExampleDensity_MeasureMixed() {
+	qsim := q.NewDensity()
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+
+	qsim.H(q0).CNOT(q0, q1)
+	qsim.MeasureMixed(q0)
+
+	fmt.Printf("%.4f\n", qsim.Probability())
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("ZZ")))
+	fmt.Printf("%.4f\n", qsim.DensityMatrix().Purity())
+
+	// Output:
+	// [0.5000 0.0000 0.0000 0.5000]
+	// 1.0000
+	// 0.5000
+}
+
+func ExampleDensity_Reset() {
+	qsim := q.NewDensity()
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+
+	qsim.H(q0).CNOT(q0, q1)
+	qsim.Reset(q0)
+
+	fmt.Printf("%.4f\n", qsim.Probability())
+
+	// Output:
+	// [0.5000 0.5000 0.0000 0.0000]
+}
+
+func ExampleDensity_SetNoise() {
+	qsim := q.NewDensity()
+	qsim.SetNoise(noise.New().
+		Add(channel.Depolarizing(0.3, 0), "H").
+		SetIdle(channel.AmplitudeDamping(0.1, 0)))
+
+	q0 := qsim.Zero()
+	qsim.One()
+
+	qsim.H(q0)
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("XI")))
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("IZ")))
+
+	// Output:
+	// 0.6000
+	// -0.8000
+}
+
+func TestDensity_SetNoise(t *testing.T) {
+	cases := []struct {
+		model *noise.Model
+		build func(qsim *q.Density, qb []q.Qubit)
+		want  *density.DensityMatrix
+	}{
+		{
+			model: nil,
+			build: func(qsim *q.Density, qb []q.Qubit) {
+				qsim.H(qb[0]).CNOT(qb[0], qb[1])
+			},
+			want: func() *density.DensityMatrix {
+				qsim := q.NewDensity()
+				qb := qsim.Zeros(2)
+				qsim.H(qb[0]).CNOT(qb[0], qb[1])
+				return qsim.DensityMatrix()
+			}(),
+		},
+		{
+			model: noise.New().Add(channel.BitFlip(0.2, 1), "ControlledX"),
+			build: func(qsim *q.Density, qb []q.Qubit) {
+				qsim.H(qb[0]).CNOT(qb[0], qb[1])
+			},
+			want: func() *density.DensityMatrix {
+				qsim := q.NewDensity()
+				qb := qsim.Zeros(2)
+				qsim.H(qb[0]).CNOT(qb[0], qb[1]).BitFlip(0.2, qb[1])
+				return qsim.DensityMatrix()
+			}(),
+		},
+		{
+			model: noise.New().AddQubits(channel.BitFlip(0.2, 0), []int{1}, "X"),
+			build: func(qsim *q.Density, qb []q.Qubit) {
+				qsim.X(qb...)
+			},
+			want: func() *density.DensityMatrix {
+				qsim := q.NewDensity()
+				qb := qsim.Zeros(2)
+				qsim.X(qb...).BitFlip(0.2, qb[1])
+				return qsim.DensityMatrix()
+			}(),
+		},
+		{
+			model: noise.New().Add(channel.Depolarizing(0.1, 1), "Apply"),
+			build: func(qsim *q.Density, qb []q.Qubit) {
+				qsim.Apply(gate.TensorProduct(gate.H(), 2, []int{0}))
+			},
+			want: func() *density.DensityMatrix {
+				qsim := q.NewDensity()
+				qb := qsim.Zeros(2)
+				qsim.H(qb[0]).Depolarizing(0.1, qb[1])
+				return qsim.DensityMatrix()
+			}(),
+		},
+		{
+			model: noise.New().SetIdle(channel.PhaseDamping(0.5, 0)),
+			build: func(qsim *q.Density, qb []q.Qubit) {
+				qsim.H(qb...).I(qb[0])
+			},
+			want: func() *density.DensityMatrix {
+				qsim := q.NewDensity()
+				qb := qsim.Zeros(2)
+				qsim.H(qb[0]).PhaseDamping(0.5, qb[1])
+				qsim.H(qb[1]).PhaseDamping(0.5, qb[0])
+				qsim.PhaseDamping(0.5, qb[1])
+				return qsim.DensityMatrix()
+			}(),
+		},
+	}
+
+	for _, c := range cases {
+		qsim := q.NewDensity()
+		qsim.SetNoise(c.model)
+
+		c.build(qsim, qsim.Zeros(2))
+		if !qsim.DensityMatrix().Equal(c.want) {
+			t.Errorf("got=%v, want=%v", qsim.DensityMatrix(), c.want)
+		}
+	}
+}
+
+func TestDensity_SetNoise_Idle(t *testing.T) {
+	// the idle noise is applied once per operation, not per layer.
+	m := noise.New().SetIdle(channel.AmplitudeDamping(0.2, 0))
+
+	c := q.NewCircuit()
+	qsim := q.NewDensity()
+	qsim.SetNoise(m)
+	qsim.Record(c)
+	qb := qsim.Zeros(3)
+	qsim.X(qb...)
+
+	want := q.NewDensity()
+	wb := want.Zeros(3)
+	want.X(wb[0]).AmplitudeDamping(0.2, wb[1], wb[2])
+	want.X(wb[1]).AmplitudeDamping(0.2, wb[0], wb[2])
+	want.X(wb[2]).AmplitudeDamping(0.2, wb[0], wb[1])
+
+	if !qsim.DensityMatrix().Equal(want.DensityMatrix()) {
+		t.Errorf("got=%v, want=%v", qsim.DensityMatrix(), want.DensityMatrix())
+	}
+
+	// <Z> of q0 is 1 - 2*0.8^2 after the two idle rounds during X(q1) and X(q2).
+	zii := number.Must(qsim.ExpectPauli("ZII"))
+	if !epsilon.IsCloseF64(zii, 1-2*0.8*0.8) {
+		t.Errorf("got=%v, want=%v", zii, 1-2*0.8*0.8)
+	}
+
+	tsim := q.NewTrajectory(m)
+	tsim.SetSeed(1)
+	if got := number.Must(tsim.ExpectPauli(c, 2000, "ZII")); math.Abs(got-zii) > 0.05 {
+		t.Errorf("got=%v, want=%v", got, zii)
+	}
+}
+
+func TestDensity_SetNoise_Readout(t *testing.T) {
+	qsim := q.NewDensity()
+	qsim.SetNoise(noise.New().SetReadout(readout.New(readout.Assignment(1, 0))))
+
+	qb := qsim.Zeros(2)
+	if got := qsim.Measure(qb...).BinaryString(); got != "10" {
+		t.Errorf("got=%v, want=10", got)
+	}
+
+	if p := qsim.Probability()[0]; math.Abs(p-1) > 1e-12 {
+		t.Errorf("got=%v, want=1", p)
+	}
+}
+
+func TestDensity(t *testing.T) {
+	build := func(qsim *q.Q, qb []q.Qubit) {
+		qsim.U(1.0, 2.0, 3.0, qb[0]).H(qb[1]).X(qb[2])
+		qsim.Y(qb[0]).Z(qb[1]).S(qb[2]).T(qb[0]).I(qb[1])
+		qsim.R(0.1, qb[0]).RX(0.2, qb[1]).RY(0.3, qb[2]).RZ(0.4, qb[0])
+		qsim.G(gate.RY(0.5), qb[1])
+		qsim.CU(0.6, 0.7, 0.8, qb[0], qb[1]).CR(0.9, qb[1], qb[2])
+		qsim.ControlledH([]q.Qubit{qb[2]}, []q.Qubit{qb[0]})
+		qsim.C(gate.RX(1.0), qb[1], qb[0]).CCNOT(qb[0], qb[1], qb[2]).CZ(qb[2], qb[0])
+		qsim.ApplyAt(gate.CNOT(2, 0, 1), qb[2], qb[1])
+		qsim.Apply(gate.TensorProduct(gate.H(), 3, []int{2}))
+		qsim.Swap(qb[0], qb[2])
+		F.QFT(qsim, qb...)
+	}
+
+	c := q.NewCircuit()
+	want := q.New()
+	want.Record(c)
+	build(want, want.Zeros(3))
+
+	rho := density.New(want.Qubit())
+	for _, run := range []func() *q.Density{
+		func() *q.Density {
+			got := q.NewDensity()
+			got.Run(c)
+			return got
+		},
+		func() *q.Density {
+			// replay the recorded circuit of the same calls on Density.
+			rec := q.NewCircuit()
+			got := q.NewDensity()
+			got.Record(rec)
+			got.Run(c)
+
+			replay := q.NewDensity()
+			replay.Run(rec)
+			return replay
+		},
+	} {
+		got := run()
+		if !got.DensityMatrix().Equal(rho) {
+			t.Errorf("got=%v, want=%v", got.DensityMatrix(), rho)
+		}
+	}
+}
+
+func TestDensity_Measure(t *testing.T) {
+	cases := []struct {
+		seed uint64
+	}{
+		{1}, {2}, {3}, {4}, {5},
+	}
+
+	for _, c := range cases {
+		qsim := q.NewDensity()
+		qsim.SetRand(rand.Const(c.seed))
+
+		qb := qsim.Zeros(3)
+		qsim.H(qb[0]).CNOT(qb[0], qb[1]).CNOT(qb[1], qb[2])
+		qsim.AmplitudeDamping(0.2, qb[2])
+
+		m := qsim.Measure(qb...).BinaryString()
+		if p := qsim.Probability()[number.MustParseInt(m)]; math.Abs(p-1) > 1e-12 {
+			t.Errorf("%v: got=%v, want=1", m, p)
+		}
+
+		if counts := qsim.Sample(10); counts[m] != 10 {
+			t.Errorf("got=%v, want=%v", counts, m)
+		}
+	}
+}
+
+func TestDensity_ExpectPauli_error(t *testing.T) {
+	cases := []struct {
+		s    string
+		qb   []q.Qubit
+		want string
+	}{
+		{"A", nil, `invalid Pauli operator 'A' in "A"`},
+		{"ZZZ", nil, `invalid length of "ZZZ": 2 qubits`},
+		{"ZZ", []q.Qubit{0}, `invalid number of qubits: 1 for "ZZ"`},
+		{"Z", []q.Qubit{2}, "index 2 out of range for 2 qubits"},
+		{"ZX", []q.Qubit{1, 1}, "duplicate index 1"},
+	}
+
+	qsim := q.NewDensity()
+	qsim.Zeros(2)
+	for _, c := range cases {
+		if _, err := qsim.ExpectPauli(c.s, c.qb...); err == nil || err.Error() != c.want {
+			t.Errorf("%v%v: got=%v, want=%v", c.s, c.qb, err, c.want)
+		}
+	}
+}
+
+func TestDensity_empty(t *testing.T) {
+	qsim := q.NewDensity()
+
+	if got := qsim.Probability(); len(got) != 0 {
+		t.Errorf("got=%v, want=[]", got)
+	}
+
+	if got := qsim.Expect(matrix.Identity(1)); got != 0 {
+		t.Errorf("got=%v, want=0", got)
+	}
+
+	if got, err := qsim.ExpectPauli(""); err != nil || got != 0 {
+		t.Errorf("got=%v, %v, want=0", got, err)
+	}
+}
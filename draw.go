@@ -0,0 +1,249 @@
+package q
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/itsubaki/q/math/number"
+)
+
+// Draw returns the text diagram of c.
+// Each qubit is drawn as a wire and the classical bits as the wire c at the bottom.
+// If width is positive, the diagram is wrapped so that no line exceeds width characters.
+func (c *Circuit) Draw(width int) string {
+	n := c.NumQubits
+	for _, op := range c.Ops {
+		if op.Type == OpApply {
+			n = max(n, number.Log2(op.Matrix.Rows))
+		}
+	}
+
+	d := newDiagram(n, c.NumClbits > 0)
+	for _, op := range c.Ops {
+		d.add(op)
+	}
+
+	return d.draw(width)
+}
+
+// diagram is a circuit diagram of n qubits.
+// The row i < n is the wire of the i-th qubit and the row n is the classical wire.
+// In text, the row r is the line 2r and the line 2r+1 is the space below it.
+type diagram struct {
+	n      int
+	clbits bool
+	level  []int      // the number of layers occupied by each row
+	layers [][]string // the cells of each line in each layer
+}
+
+func newDiagram(n int, clbits bool) *diagram {
+	return &diagram{
+		n:      n,
+		clbits: clbits,
+		level:  make([]int, n+1),
+	}
+}
+
+// add places op in the first layer after the layers occupied by the rows it spans.
+func (d *diagram) add(op Op) {
+	label := d.labels(op)
+	if len(label) == 0 {
+		return
+	}
+
+	lo, hi := d.n, 0
+	for r := range label {
+		lo, hi = min(lo, r), max(hi, r)
+	}
+
+	var layer int
+	for r := lo; r <= hi; r++ {
+		layer = max(layer, d.level[r])
+	}
+
+	for r := lo; r <= hi; r++ {
+		d.level[r] = layer + 1
+	}
+
+	for len(d.layers) <= layer {
+		d.layers = append(d.layers, make([]string, 2*d.n+1))
+	}
+
+	vertical := "|"
+	if op.Type == OpBarrier {
+		vertical = ":"
+	}
+
+	cells := d.layers[layer]
+	for r := lo; r <= hi; r++ {
+		if r < hi {
+			cells[2*r+1] = vertical
+		}
+
+		if s, ok := label[r]; ok {
+			cells[2*r] = s
+			continue
+		}
+
+		cells[2*r] = "+"
+	}
+}
+
+// labels returns the labels of op keyed by row.
+func (d *diagram) labels(op Op) map[int]string {
+	label := make(map[int]string)
+	for _, qb := range op.Control {
+		label[qb.Index()] = "*"
+	}
+
+	name := string(op.Type)
+	switch op.Type {
+	case OpNew:
+		name = "|psi>"
+		if len(op.State) == 2 && op.State[1] == 0 {
+			name = "|0>"
+		}
+
+		if len(op.State) == 2 && op.State[0] == 0 {
+			name = "|1>"
+		}
+	case OpApply:
+		for i := range number.Log2(op.Matrix.Rows) {
+			label[i] = "U"
+		}
+	case OpG, OpControlled:
+		name = "G"
+	case OpU, OpControlledU:
+		name = "U" + params(op.Params)
+	case OpR, OpControlledR:
+		name = "R" + params(op.Params)
+	case OpRX, OpRY, OpRZ:
+		name += params(op.Params)
+	case OpControlledH:
+		name = "H"
+	case OpControlledX:
+		name = "X"
+	case OpControlledZ:
+		name = "Z"
+	case OpSwap:
+		name = "x"
+	case OpMeasure:
+		name = "M"
+		label[d.n] = "c[" + join(op.Clbit) + "]"
+	case OpReset:
+		name = "|0>"
+	case OpBarrier:
+		name = ":"
+		if len(op.Target) == 0 {
+			for i := range d.n {
+				label[i] = name
+			}
+		}
+	}
+
+	for i, qb := range op.Target {
+		label[qb.Index()] = name
+		if op.Type == OpApplyAt {
+			// the subscript is the position of the qubit in the gate.
+			label[qb.Index()] = fmt.Sprintf("U%d", i)
+		}
+	}
+
+	if op.Cond != nil {
+		label[d.n] = fmt.Sprintf("c[%s]==%d", join(op.Cond.Clbit), op.Cond.Value)
+	}
+
+	return label
+}
+
+// draw returns the text of d wrapped at width.
+func (d *diagram) draw(width int) string {
+	if d.n == 0 {
+		return ""
+	}
+
+	lines := 2*d.n + 1
+	if !d.clbits {
+		lines = 2*d.n - 1
+	}
+
+	w := len(fmt.Sprintf("q%d", max(d.n-1, 0)))
+	prefix := make([]string, lines)
+	fill := make([]byte, lines)
+	for i := range lines {
+		switch {
+		case i == 2*d.n:
+			prefix[i], fill[i] = fmt.Sprintf("%-*s: ", w, "c"), '='
+		case i%2 == 0:
+			prefix[i], fill[i] = fmt.Sprintf("%-*s: ", w, fmt.Sprintf("q%d", i/2)), '-'
+		default:
+			prefix[i], fill[i] = strings.Repeat(" ", w+2), ' '
+		}
+	}
+
+	var pages []string
+	for start := 0; start < len(d.layers) || start == 0; {
+		sb := make([]strings.Builder, lines)
+		for i := range lines {
+			sb[i].WriteString(prefix[i])
+		}
+
+		end := start
+		for ; end < len(d.layers); end++ {
+			cw := 1
+			for i := range lines {
+				cw = max(cw, len(d.layers[end][i]))
+			}
+
+			if width > 0 && end > start && sb[0].Len()+cw+2 > width {
+				break
+			}
+
+			for i := range lines {
+				sb[i].WriteString(center(d.layers[end][i], cw, fill[i]))
+			}
+		}
+
+		page := make([]string, lines)
+		for i := range lines {
+			page[i] = strings.TrimRight(sb[i].String(), " ")
+		}
+
+		pages = append(pages, strings.Join(page, "\n"))
+		if end == start {
+			break
+		}
+
+		start = end
+	}
+
+	return strings.Join(pages, "\n\n")
+}
+
+// center returns s centered in a cell of width w+2 padded with fill.
+func center(s string, w int, fill byte) string {
+	left := (w - len(s)) / 2
+	right := w - len(s) - left
+
+	f := string(fill)
+	return f + strings.Repeat(f, left) + s + strings.Repeat(f, right) + f
+}
+
+func params(p []float64) string {
+	list := make([]string, len(p))
+	for i := range p {
+		list[i] = strconv.FormatFloat(p[i], 'g', 4, 64)
+	}
+
+	return "(" + strings.Join(list, ",") + ")"
+}
+
+func join(v []int) string {
+	list := make([]string, len(v))
+	for i := range v {
+		list[i] = strconv.Itoa(v[i])
+	}
+
+	return strings.Join(list, ",")
+}
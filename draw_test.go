@@ -0,0 +1,124 @@
+package q_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q"
+	F "github.com/itsubaki/q/function"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+func ExampleCircuit_Draw() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	q2 := qsim.Zero()
+	qsim.H(q0)
+	qsim.CNOT(q0, q2)
+	qsim.RX(0.5, q1)
+	qsim.CCNOT(q0, q1, q2)
+	qsim.Swap(q0, q2)
+	qsim.Measure(q0, q1, q2)
+
+	fmt.Println(c.Draw(0))
+
+	// Output:
+	// q0: -|0>--H--*-----------*--x---M---------------
+	//              |           |  |   |
+	// q1: -|0>-----+--RX(0.5)--*--+---+-----M---------
+	//              |           |  |   |     |
+	// q2: -|0>-----X-----------X--x---+-----+-----M---
+	//                                 |     |     |
+	// c : ===========================c[0]==c[1]==c[2]=
+}
+
+func ExampleCircuit_Draw_wrap() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	qb := qsim.Zeros(3)
+	F.QFT(qsim, qb...)
+
+	fmt.Println(c.Draw(40))
+
+	// Output:
+	// q0: -|0>--H-----*----------*--------
+	//                 |          |
+	// q1: -|0>-----R(1.571)------+------H-
+	//                            |
+	// q2: -|0>---------------R(0.7854)----
+	//
+	// q0: -------------
+	//
+	// q1: ----*--------
+	//         |
+	// q2: -R(1.571)--H-
+}
+
+func ExampleCircuit_Draw_cond() {
+	c := q.NewCircuit().Add(
+		q.Op{Type: q.OpH, Target: []q.Qubit{0}},
+		q.Op{Type: q.OpBarrier},
+		q.Op{Type: q.OpMeasure, Target: []q.Qubit{0}, Clbit: []int{0}},
+		q.Op{Type: q.OpControlled, Control: []q.Qubit{2}, Target: []q.Qubit{0}, Matrix: gate.S()},
+		q.Op{Type: q.OpX, Target: []q.Qubit{1}, Cond: &q.Condition{Clbit: []int{0}, Value: 1}},
+		q.Op{Type: q.OpReset, Target: []q.Qubit{0}},
+	)
+
+	fmt.Println(c.Draw(0))
+
+	// Output:
+	// q0: -H--:---M----G----|0>---
+	//         :   |    |
+	// q1: ----:---+----+-----X----
+	//         :   |    |     |
+	// q2: ----:---+----*-----+----
+	//             |          |
+	// c : =======c[0]=====c[0]==1=
+}
+
+func TestDraw(t *testing.T) {
+	cases := []struct {
+		in    *q.Circuit
+		width int
+		want  string
+	}{
+		{
+			q.NewCircuit(),
+			0,
+			"",
+		},
+		{
+			q.NewCircuit().Add(q.Op{Type: q.OpApply, Matrix: gate.CNOT(2, 0, 1)}),
+			0,
+			"q0: -U-\n     |\nq1: -U-",
+		},
+		{
+			q.NewCircuit().Add(q.Op{Type: q.OpApplyAt, Target: []q.Qubit{2, 0}, Matrix: gate.CNOT(2, 0, 1)}),
+			0,
+			"q0: -U1-\n     |\nq1: -+--\n     |\nq2: -U0-",
+		},
+		{
+			q.NewCircuit().Add(
+				q.Op{Type: q.OpU, Target: []q.Qubit{0}, Params: []float64{1, 2, 3}},
+				q.Op{Type: q.OpY, Target: []q.Qubit{0}},
+			),
+			5,
+			"q0: -U(1,2,3)-\n\nq0: -Y-",
+		},
+	}
+
+	for _, c := range cases {
+		got := c.in.Draw(c.width)
+		if got != c.want {
+			t.Errorf("got=%q, want=%q", got, c.want)
+		}
+	}
+}
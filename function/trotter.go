@@ -0,0 +1,137 @@
+package function
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/itsubaki/q"
+	"github.com/itsubaki/q/quantum/observable"
+)
+
+// PauliExp applies exp(-i * theta * P) up to a global phase, where P is the Pauli string such as "XZI".
+// The i-th character of pauli acts on qb[i].
+// It returns an error if pauli contains a character other than I, X, Y and Z, or its length is not the number of qubits.
+func PauliExp(qsim *q.Q, theta float64, pauli string, qb ...q.Qubit) error {
+	if err := validate(pauli, len(qb)); err != nil {
+		return err
+	}
+
+	pauliExp(qsim, theta, pauli, qb)
+	return nil
+}
+
+// pauliExp applies exp(-i * theta * P) for the valid Pauli string.
+func pauliExp(qsim *q.Q, theta float64, pauli string, qb []q.Qubit) {
+	var active []q.Qubit
+	for i, c := range pauli {
+		if c == 'I' {
+			continue
+		}
+
+		active = append(active, qb[i])
+	}
+
+	if len(active) == 0 {
+		return
+	}
+
+	// change the basis to Z.
+	basis := func(sign float64) {
+		for i, c := range pauli {
+			switch c {
+			case 'X':
+				qsim.H(qb[i])
+			case 'Y':
+				qsim.RX(sign*math.Pi/2, qb[i])
+			}
+		}
+	}
+
+	basis(1)
+	for i := 0; i < len(active)-1; i++ {
+		qsim.CNOT(active[i], active[i+1])
+	}
+
+	qsim.RZ(2*theta, active[len(active)-1])
+
+	for i := len(active) - 2; i >= 0; i-- {
+		qsim.CNOT(active[i], active[i+1])
+	}
+	basis(-1)
+}
+
+// Trotter applies the first-order Trotter-Suzuki approximation of exp(-i * h * t) with the given number of steps.
+// If steps is not positive, it is treated as one.
+// The coefficients of h must be real. If no qubits are given, the i-th character of the Pauli strings acts on the i-th qubit.
+// It returns an error if a Pauli string of h is invalid as in PauliExp.
+func Trotter(qsim *q.Q, h observable.PauliSum, t float64, steps int, qb ...q.Qubit) error {
+	qb, steps = qubits(h, qb), max(steps, 1)
+	for _, term := range h {
+		if err := validate(term.Pauli, len(qb)); err != nil {
+			return err
+		}
+	}
+
+	dt := t / float64(steps)
+	for range steps {
+		for _, term := range h {
+			pauliExp(qsim, real(term.Coef)*dt, term.Pauli, qb)
+		}
+	}
+
+	return nil
+}
+
+// Trotter2 applies the second-order Trotter-Suzuki approximation of exp(-i * h * t) with the given number of steps.
+// If steps is not positive, it is treated as one.
+// The coefficients of h must be real. If no qubits are given, the i-th character of the Pauli strings acts on the i-th qubit.
+// It returns an error if a Pauli string of h is invalid as in PauliExp.
+func Trotter2(qsim *q.Q, h observable.PauliSum, t float64, steps int, qb ...q.Qubit) error {
+	qb, steps = qubits(h, qb), max(steps, 1)
+	for _, term := range h {
+		if err := validate(term.Pauli, len(qb)); err != nil {
+			return err
+		}
+	}
+
+	dt := t / float64(steps)
+	for range steps {
+		for _, term := range h {
+			pauliExp(qsim, real(term.Coef)*dt/2, term.Pauli, qb)
+		}
+
+		for i := len(h) - 1; i >= 0; i-- {
+			pauliExp(qsim, real(h[i].Coef)*dt/2, h[i].Pauli, qb)
+		}
+	}
+
+	return nil
+}
+
+// validate returns an error if pauli is not a Pauli string on n qubits.
+func validate(pauli string, n int) error {
+	if len(pauli) != n {
+		return fmt.Errorf("invalid length of %q: %d qubits", pauli, n)
+	}
+
+	for _, c := range pauli {
+		if c != 'I' && c != 'X' && c != 'Y' && c != 'Z' {
+			return fmt.Errorf("invalid Pauli operator %q in %q", c, pauli)
+		}
+	}
+
+	return nil
+}
+
+func qubits(h observable.PauliSum, qb []q.Qubit) []q.Qubit {
+	if len(qb) > 0 {
+		return qb
+	}
+
+	qb = make([]q.Qubit, h.NumQubits())
+	for i := range qb {
+		qb[i] = q.Qubit(i)
+	}
+
+	return qb
+}
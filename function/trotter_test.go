@@ -0,0 +1,173 @@
+package function_test
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/itsubaki/q"
+	F "github.com/itsubaki/q/function"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/observable"
+)
+
+func ExampleTrotter() {
+	// transverse field Ising model on 3 qubits.
+	h := observable.PauliSum{
+		{Coef: -1, Pauli: "ZZI"}, {Coef: -1, Pauli: "IZZ"},
+		{Coef: -0.5, Pauli: "XII"}, {Coef: -0.5, Pauli: "IXI"}, {Coef: -0.5, Pauli: "IIX"},
+	}
+
+	t := 1.0
+	exact := vector.New(1, 0, 0, 0, 0, 0, 0, 0).Apply(h.Evolution(t, 1000))
+
+	for _, steps := range []int{1, 10, 100} {
+		qsim := q.New()
+		qb := qsim.Zeros(3)
+		if err := F.Trotter(qsim, h, t, steps, qb...); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		got := vector.New(qsim.Amplitude()...)
+		fmt.Printf("%d: %.6f\n", steps, 1-math.Pow(cmplx.Abs(got.InnerProduct(exact)), 2))
+	}
+
+	// Output:
+	// 1: 0.495677
+	// 10: 0.003117
+	// 100: 0.000031
+}
+
+func ExampleTrotter2() {
+	h := observable.PauliSum{
+		{Coef: -1, Pauli: "ZZI"}, {Coef: -1, Pauli: "IZZ"},
+		{Coef: -0.5, Pauli: "XII"}, {Coef: -0.5, Pauli: "IXI"}, {Coef: -0.5, Pauli: "IIX"},
+	}
+
+	t := 1.0
+	exact := vector.New(1, 0, 0, 0, 0, 0, 0, 0).Apply(h.Evolution(t, 1000))
+
+	for _, steps := range []int{1, 10, 100} {
+		qsim := q.New()
+		qb := qsim.Zeros(3)
+		if err := F.Trotter2(qsim, h, t, steps, qb...); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		got := vector.New(qsim.Amplitude()...)
+		fmt.Printf("%d: %.6f\n", steps, 1-math.Pow(cmplx.Abs(got.InnerProduct(exact)), 2))
+	}
+
+	// Output:
+	// 1: 0.135606
+	// 10: 0.000006
+	// 100: 0.000000
+}
+
+func TestPauliExp(t *testing.T) {
+	r := rand.Const(1)
+	for _, s := range []string{"X", "Y", "Z", "I", "XX", "YZ", "ZIY", "XYZ", "IIX", "YYYY"} {
+		n := len(s)
+		theta := r() * 2 * math.Pi
+
+		qsim := q.New()
+		qb := qsim.Zeros(n)
+		for i := range qb {
+			qsim.U(r()*math.Pi, r()*math.Pi, r()*math.Pi, qb[i])
+		}
+
+		// exp(-i * theta * P) = cos(theta) * I - i * sin(theta) * P
+		u := matrix.Identity(1 << n).Mul(complex(math.Cos(theta), 0)).Add(observable.Pauli(s).Mul(complex(0, -math.Sin(theta))))
+		want := vector.New(qsim.Amplitude()...).Apply(u)
+
+		if err := F.PauliExp(qsim, theta, s, qb...); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s == "I" {
+			want = want.Mul(cmplx.Exp(complex(0, theta)))
+		}
+
+		if got := vector.New(qsim.Amplitude()...); !got.Equal(want) {
+			t.Errorf("%v: got=%v, want=%v", s, got, want)
+		}
+	}
+}
+
+func TestTrotter_steps(t *testing.T) {
+	h := observable.PauliSum{{Coef: -1, Pauli: "ZZ"}, {Coef: -0.5, Pauli: "XI"}, {Coef: -0.5, Pauli: "IX"}}
+
+	cases := []struct {
+		trotter func(qsim *q.Q, h observable.PauliSum, t float64, steps int, qb ...q.Qubit) error
+		steps   int
+	}{
+		{F.Trotter, 0},
+		{F.Trotter, -1},
+		{F.Trotter2, 0},
+		{F.Trotter2, -3},
+	}
+
+	for _, c := range cases {
+		qsim := q.New()
+		qsim.Zeros(2)
+		if err := c.trotter(qsim, h, 0.7, c.steps); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := q.New()
+		want.Zeros(2)
+		if err := c.trotter(want, h, 0.7, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, exp := vector.New(qsim.Amplitude()...), vector.New(want.Amplitude()...)
+		if !got.Equal(exp) {
+			t.Errorf("%v: got=%v, want=%v", c.steps, got, exp)
+		}
+	}
+}
+
+func TestPauliExp_error(t *testing.T) {
+	cases := []struct {
+		pauli string
+		n     int
+		want  string
+	}{
+		{"XQ", 2, `invalid Pauli operator 'Q' in "XQ"`},
+		{"xz", 2, `invalid Pauli operator 'x' in "xz"`},
+		{"XZ", 3, `invalid length of "XZ": 3 qubits`},
+		{"XZI", 2, `invalid length of "XZI": 2 qubits`},
+	}
+
+	for _, c := range cases {
+		qsim := q.New()
+		qb := qsim.Zeros(c.n)
+		if err := F.PauliExp(qsim, 0.1, c.pauli, qb...); err == nil || err.Error() != c.want {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+
+		// the state is not changed.
+		if p := qsim.Probability(); p[0] != 1 {
+			t.Errorf("got=%v", p)
+		}
+	}
+}
+
+func TestTrotter_error(t *testing.T) {
+	h := observable.PauliSum{{Coef: 1, Pauli: "ZZ"}, {Coef: 0.5, Pauli: "XA"}}
+	for _, trotter := range []func(qsim *q.Q, h observable.PauliSum, t float64, steps int, qb ...q.Qubit) error{F.Trotter, F.Trotter2} {
+		qsim := q.New()
+		qb := qsim.Zeros(2)
+		if err := trotter(qsim, h, 1, 1, qb...); err == nil || err.Error() != `invalid Pauli operator 'A' in "XA"` {
+			t.Errorf("got=%v", err)
+		}
+
+		if err := trotter(qsim, h[:1], 1, 1, qb[0]); err == nil || err.Error() != `invalid length of "ZZ": 1 qubits` {
+			t.Errorf("got=%v", err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package q
+
+import (
+	"sort"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+// Fuse returns a circuit with the consecutive gates of c merged into ApplyAt operations on at most width qubits.
+// A gate is merged with the pending gates that share a qubit with it if they fit in width together.
+// The gates on disjoint qubits are not merged and stay in separate operations.
+// Measurements, resets, barriers, conditional operations and Apply are not merged.
+func (c *Circuit) Fuse(width int) *Circuit {
+	out := &Circuit{
+		NumQubits: c.NumQubits,
+		NumClbits: c.NumClbits,
+		Ops:       make([]Op, 0, len(c.Ops)),
+	}
+
+	// blocks are the gates not yet emitted. The qubits of the blocks are disjoint.
+	var blocks []*block
+	flush := func(all bool, qb []Qubit) {
+		var rest []*block
+		for _, b := range blocks {
+			if !all && !b.overlaps(qb) {
+				rest = append(rest, b)
+				continue
+			}
+
+			out.Ops = append(out.Ops, b.op())
+		}
+
+		blocks = rest
+	}
+
+	for _, op := range c.Ops {
+		qb := op.Qubits()
+		if !fusible(op, width) {
+			flush(op.Type == OpApply || (op.Type == OpBarrier && len(qb) == 0), qb)
+			out.Ops = append(out.Ops, op)
+			continue
+		}
+
+		merged := &block{qubits: qb}
+		var rest []*block
+		for _, b := range blocks {
+			if b.overlaps(qb) {
+				merged.qubits = append(merged.qubits, b.qubits...)
+				merged.ops = append(merged.ops, b.ops...)
+				continue
+			}
+
+			rest = append(rest, b)
+		}
+
+		merged.qubits = unique(merged.qubits)
+		if len(merged.qubits) > width {
+			flush(false, qb)
+			merged = &block{qubits: unique(qb)}
+		} else {
+			blocks = rest
+		}
+
+		merged.ops = append(merged.ops, op)
+		blocks = append(blocks, merged)
+	}
+
+	flush(true, nil)
+	return out
+}
+
+// block is a sequence of gates to be merged.
+type block struct {
+	qubits []Qubit
+	ops    []Op
+}
+
+// overlaps returns true if b acts on any of qb.
+func (b *block) overlaps(qb []Qubit) bool {
+	for _, q := range qb {
+		for _, p := range b.qubits {
+			if p == q {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// op returns the operation of b.
+// If b has a single gate, it returns the gate as is.
+func (b *block) op() Op {
+	if len(b.ops) == 1 {
+		return b.ops[0]
+	}
+
+	return Op{Type: OpApplyAt, Target: b.qubits, Matrix: unitary(b.ops, b.qubits)}
+}
+
+// unitary returns the matrix of the gates on the qubits.
+// The first qubit corresponds to the most significant bit of the matrix.
+func unitary(ops []Op, qb []Qubit) *matrix.Matrix {
+	local := make(map[Qubit]Qubit)
+	for i := range qb {
+		local[qb[i]] = Qubit(i)
+	}
+
+	remap := func(qb []Qubit) []Qubit {
+		out := make([]Qubit, len(qb))
+		for i := range qb {
+			out[i] = local[qb[i]]
+		}
+
+		return out
+	}
+
+	// the j-th column is the state the gates map the j-th basis state to.
+	dim := 1 << len(qb)
+	u := matrix.Zero(dim, dim)
+	for j := range dim {
+		v := make([]complex128, dim)
+		v[j] = 1
+
+		sim := &Q{qb: qubit.New(vector.New(v...))}
+		for _, op := range ops {
+			op.Control, op.Target = remap(op.Control), remap(op.Target)
+			sim.apply(op, nil)
+		}
+
+		for i, a := range sim.Amplitude() {
+			u.Set(i, j, a)
+		}
+	}
+
+	return u
+}
+
+// fusible returns true if op is a gate on at most width qubits.
+func fusible(op Op, width int) bool {
+	if !op.IsUnitary() || op.Cond != nil {
+		return false
+	}
+
+	switch op.Type {
+	case OpApply, OpBarrier:
+		return false
+	}
+
+	return len(unique(op.Qubits())) <= width
+}
+
+// unique returns the sorted qubits without duplicates.
+func unique(qb []Qubit) []Qubit {
+	out := make([]Qubit, 0, len(qb))
+	seen := make(map[Qubit]bool)
+	for _, q := range qb {
+		if seen[q] {
+			continue
+		}
+
+		seen[q] = true
+		out = append(out, q)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,119 @@
+package q_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q"
+	F "github.com/itsubaki/q/function"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleCircuit_Fuse() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	qb := qsim.Zeros(3)
+	qsim.H(qb[0]).T(qb[0]).H(qb[0])
+	qsim.H(qb[1]).CNOT(qb[0], qb[1])
+	qsim.X(qb[2])
+
+	fused := c.Fuse(2)
+	fmt.Println(fused)
+
+	out := q.New()
+	out.Run(fused)
+	fmt.Println(qubit.Equal(out.State(), qsim.State()))
+
+	// Output:
+	// New [0]
+	// New [1]
+	// New [2]
+	// ApplyAt [0 1]
+	// X [2]
+	// true
+}
+
+func TestCircuit_Fuse(t *testing.T) {
+	cases := []struct {
+		build func(qsim *q.Q, c *q.Circuit, qb []q.Qubit)
+		width int
+		len   int
+	}{
+		{
+			func(qsim *q.Q, c *q.Circuit, qb []q.Qubit) {
+				F.QFT(qsim, qb...)
+			},
+			2,
+			11,
+		},
+		{
+			func(qsim *q.Q, c *q.Circuit, qb []q.Qubit) {
+				F.QFT(qsim, qb...)
+			},
+			4,
+			5,
+		},
+		{
+			func(qsim *q.Q, c *q.Circuit, qb []q.Qubit) {
+				qsim.H(qb...).T(qb...).H(qb...)
+				qsim.CCNOT(qb[0], qb[1], qb[2])
+				qsim.Swap(qb[3], qb[0])
+			},
+			1,
+			10,
+		},
+		{
+			func(qsim *q.Q, c *q.Circuit, qb []q.Qubit) {
+				qsim.H(qb...).CNOT(qb[0], qb[1])
+				qsim.Measure(qb[1])
+				qsim.Measure(qb[2])
+				c.Add(q.Op{Type: q.OpX, Target: []q.Qubit{qb[3]}, Cond: &q.Condition{Clbit: []int{1}, Value: 1}})
+				qsim.RY(0.3, qb[0]).RZ(0.4, qb[1])
+				c.Add(q.Op{Type: q.OpBarrier})
+				qsim.U(0.1, 0.2, 0.3, qb[2]).ApplyAt(gate.CZ(2, 0, 1), qb[3], qb[2])
+				qsim.Apply(gate.TensorProduct(gate.H(), 4, []int{1}))
+				qsim.S(qb[0]).S(qb[0])
+				qsim.Reset(qb[2])
+			},
+			2,
+			17,
+		},
+	}
+
+	for _, c := range cases {
+		circuit := q.NewCircuit()
+
+		qsim := q.New()
+		qsim.SetRand(rand.Const(1))
+		qsim.Record(circuit)
+
+		qb := qsim.Zeros(4)
+		c.build(qsim, circuit, qb)
+
+		fused := circuit.Fuse(c.width)
+		if fused.Len() != c.len {
+			t.Errorf("got=%v, want=%v", fused.Len(), c.len)
+		}
+
+		want := q.New()
+		want.SetRand(rand.Const(2))
+		wbits := want.Run(circuit)
+
+		got := q.New()
+		got.SetRand(rand.Const(2))
+		gbits := got.Run(fused)
+
+		if !qubit.Equal(got.State(), want.State()) {
+			t.Errorf("got=%v, want=%v", got.State(), want.State())
+		}
+
+		if fmt.Sprint(gbits) != fmt.Sprint(wbits) {
+			t.Errorf("got=%v, want=%v", gbits, wbits)
+		}
+	}
+}
@@ -8,6 +8,12 @@ import (
 	"github.com/itsubaki/q/math/matrix"
 )
 
+// Iterations returns the default maximum number of iterations of Jacobi and Schur for a,
+// which is 100 times the square of the size of a.
+func Iterations(a *matrix.Matrix) int {
+	return 100 * a.Rows * a.Rows
+}
+
 // Jacobi returns the eigenvectors and eigenvalues of a matrix using the Jacobi method.
 // The input matrix a must be hermitian.
 func Jacobi(a *matrix.Matrix, iter int, tol ...float64) (vectors *matrix.Matrix, lambdas *matrix.Matrix) {
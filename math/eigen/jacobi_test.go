@@ -33,6 +33,21 @@ func Example_sqrt() {
 	// true
 }
 
+func ExampleIterations() {
+	x := matrix.New(
+		[]complex128{2, 1i},
+		[]complex128{-1i, 2},
+	)
+
+	_, d := eigen.Jacobi(x, eigen.Iterations(x))
+	fmt.Println(eigen.Iterations(x))
+	fmt.Printf("%.3f\n", d.Real())
+
+	// Output:
+	// 400
+	// [[1.000 0.000] [0.000 3.000]]
+}
+
 func TestJacobi(t *testing.T) {
 	cases := []struct {
 		in *matrix.Matrix
@@ -0,0 +1,276 @@
+package eigen
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+)
+
+// eps is the machine epsilon of float64.
+const eps = 0x1p-52
+
+// Convergence is the convergence diagnostics of an iterative method.
+type Convergence struct {
+	Converged  bool    // true if all subdiagonal elements are within the tolerance
+	Iterations int     // the number of iterations
+	Residual   float64 // the largest absolute value of the subdiagonal elements
+}
+
+// QR returns the eigenvectors and eigenvalues of a matrix using the shifted QR algorithm.
+// The input matrix a may be non-Hermitian. The eigenvectors are normalized but not orthogonal in general.
+// The eigenvectors of the repeated eigenvalues are chosen from the null space of the degenerate cluster.
+func QR(a *matrix.Matrix, iter int, tol ...float64) (vectors *matrix.Matrix, lambdas *matrix.Matrix, conv Convergence) {
+	q, t, conv := Schur(a, iter, tol...)
+	n := t.Rows
+
+	// the eigenvalues closer than small are in the same cluster.
+	small := 1e-10 * math.Max(frobenius(t), 1e-300)
+
+	// solve (t - lambda_k) y = 0 by the back substitution, and x = q * y.
+	y := matrix.Zero(n, n)
+	for k := range n {
+		lambda := t.At(k, k)
+		y.Set(k, k, 1)
+
+		for i := k - 1; i >= 0; i-- {
+			var sum complex128
+			for j := i + 1; j <= k; j++ {
+				sum += t.At(i, j) * y.At(j, k)
+			}
+
+			d := t.At(i, i) - lambda
+			if cmplx.Abs(d) > small {
+				y.Set(i, k, -sum/d)
+				continue
+			}
+
+			if cmplx.Abs(sum) <= small {
+				// the i-th component is free in the null space of the cluster.
+				y.Set(i, k, 0)
+				continue
+			}
+
+			// the defective eigenvalue has no independent eigenvector.
+			y.Set(i, k, -sum/complex(small, 0))
+		}
+	}
+
+	vectors = matrix.MatMul(q, y)
+	for k := range n {
+		var norm float64
+		for i := range n {
+			norm += real(vectors.At(i, k) * cmplx.Conj(vectors.At(i, k)))
+		}
+
+		norm = math.Sqrt(norm)
+		for i := range n {
+			vectors.DivAt(i, k, complex(norm, 0))
+		}
+	}
+
+	lambdas = matrix.Zero(n, n)
+	for i := range n {
+		lambdas.Set(i, i, t.At(i, i))
+	}
+
+	return vectors, lambdas, conv
+}
+
+// frobenius returns the Frobenius norm of a.
+func frobenius(a *matrix.Matrix) float64 {
+	var sum float64
+	for _, x := range a.Data {
+		sum += real(x * cmplx.Conj(x))
+	}
+
+	return math.Sqrt(sum)
+}
+
+// Schur returns the Schur decomposition a = q * t * q^dagger using the shifted QR algorithm,
+// where q is unitary and t is upper triangular with the eigenvalues of a on the diagonal.
+// A subdiagonal element is deflated if it is below the machine epsilon relative to the neighboring diagonal elements,
+// and the decomposition is converged if the remaining subdiagonal elements are within the tolerance.
+func Schur(a *matrix.Matrix, iter int, tol ...float64) (q, t *matrix.Matrix, conv Convergence) {
+	q, t = Hessenberg(a)
+	n := t.Rows
+	atol, _ := epsilon.Tol(tol...)
+
+	hi, stuck := n-1, 0
+	for hi > 0 && conv.Iterations < iter {
+		// find the top of the unreduced block.
+		lo := hi
+		for lo > 0 {
+			s := cmplx.Abs(t.At(lo-1, lo-1)) + cmplx.Abs(t.At(lo, lo))
+			if cmplx.Abs(t.At(lo, lo-1)) <= eps*math.Max(s, 1e-300) {
+				t.Set(lo, lo-1, 0)
+				break
+			}
+
+			lo--
+		}
+
+		if lo == hi {
+			hi, stuck = hi-1, 0
+			continue
+		}
+
+		mu := wilkinson(t.At(hi-1, hi-1), t.At(hi-1, hi), t.At(hi, hi-1), t.At(hi, hi))
+		if stuck++; stuck%10 == 0 {
+			// the exceptional shift.
+			mu = t.At(hi, hi) + complex(cmplx.Abs(t.At(hi, hi-1)), 0)
+		}
+
+		step(q, t, lo, hi, mu)
+		conv.Iterations++
+	}
+
+	// the remaining subdiagonal elements are accepted within the tolerance.
+	conv.Converged = true
+	for i := 1; i < n; i++ {
+		s := cmplx.Abs(t.At(i-1, i-1)) + cmplx.Abs(t.At(i, i))
+		if cmplx.Abs(t.At(i, i-1)) > atol*math.Max(s, 1e-300) {
+			conv.Converged = false
+		}
+
+		conv.Residual = math.Max(conv.Residual, cmplx.Abs(t.At(i, i-1)))
+		t.Set(i, i-1, 0)
+	}
+
+	return q, t, conv
+}
+
+// Hessenberg returns the Hessenberg decomposition a = q * h * q^dagger using the Householder reflections,
+// where q is unitary and h is upper Hessenberg.
+func Hessenberg(a *matrix.Matrix) (q, h *matrix.Matrix) {
+	n := a.Rows
+	q, h = matrix.Identity(n), a.Clone()
+
+	for k := range n - 2 {
+		var norm float64
+		for i := k + 1; i < n; i++ {
+			norm += real(h.At(i, k) * cmplx.Conj(h.At(i, k)))
+		}
+
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+
+		phase := complex(1, 0)
+		if x := h.At(k+1, k); x != 0 {
+			phase = x / complex(cmplx.Abs(x), 0)
+		}
+
+		v := make([]complex128, n)
+		for i := k + 1; i < n; i++ {
+			v[i] = h.At(i, k)
+		}
+		v[k+1] += phase * complex(norm, 0)
+
+		var vnorm float64
+		for i := k + 1; i < n; i++ {
+			vnorm += real(v[i] * cmplx.Conj(v[i]))
+		}
+
+		// h = p * h * p, q = q * p, where p = I - 2vv^dagger/|v|^2.
+		reflect(h, v, k+1, vnorm)
+		for _, m := range []*matrix.Matrix{h, q} {
+			for i := range n {
+				var w complex128
+				for l := k + 1; l < n; l++ {
+					w += m.At(i, l) * v[l]
+				}
+
+				w *= complex(2/vnorm, 0)
+				for l := k + 1; l < n; l++ {
+					m.SubAt(i, l, w*cmplx.Conj(v[l]))
+				}
+			}
+		}
+
+		for i := k + 2; i < n; i++ {
+			h.Set(i, k, 0)
+		}
+	}
+
+	return q, h
+}
+
+// reflect applies the Householder reflection I - 2vv^dagger/vnorm to the rows of m from k.
+func reflect(m *matrix.Matrix, v []complex128, k int, vnorm float64) {
+	for j := range m.Cols {
+		var w complex128
+		for i := k; i < m.Rows; i++ {
+			w += cmplx.Conj(v[i]) * m.At(i, j)
+		}
+
+		w *= complex(2/vnorm, 0)
+		for i := k; i < m.Rows; i++ {
+			m.SubAt(i, j, v[i]*w)
+		}
+	}
+}
+
+// step applies a QR step with the shift mu to the unreduced block t[lo:hi+1, lo:hi+1]
+// using the Givens rotations, and accumulates the rotations into q.
+func step(q, t *matrix.Matrix, lo, hi int, mu complex128) {
+	n := t.Rows
+	for i := lo; i <= hi; i++ {
+		t.SubAt(i, i, mu)
+	}
+
+	// t = g * t, where g zeroes the subdiagonal elements.
+	c, s := make([]complex128, hi), make([]complex128, hi)
+	for k := lo; k < hi; k++ {
+		x, y := t.At(k, k), t.At(k+1, k)
+		r := math.Hypot(cmplx.Abs(x), cmplx.Abs(y))
+		if r == 0 {
+			c[k], s[k] = 1, 0
+			continue
+		}
+
+		c[k], s[k] = x/complex(r, 0), y/complex(r, 0)
+		for j := k; j < n; j++ {
+			a, b := t.At(k, j), t.At(k+1, j)
+			t.Set(k, j, cmplx.Conj(c[k])*a+cmplx.Conj(s[k])*b)
+			t.Set(k+1, j, -s[k]*a+c[k]*b)
+		}
+
+		t.Set(k+1, k, 0)
+	}
+
+	// t = t * g^dagger, q = q * g^dagger, where t remains upper Hessenberg.
+	for k := lo; k < hi; k++ {
+		for _, m := range []*matrix.Matrix{t, q} {
+			rows := n
+			if m == t {
+				rows = k + 2
+			}
+
+			for i := range rows {
+				a, b := m.At(i, k), m.At(i, k+1)
+				m.Set(i, k, a*c[k]+b*s[k])
+				m.Set(i, k+1, -a*cmplx.Conj(s[k])+b*cmplx.Conj(c[k]))
+			}
+		}
+	}
+
+	for i := lo; i <= hi; i++ {
+		t.AddAt(i, i, mu)
+	}
+}
+
+// wilkinson returns the eigenvalue of the 2x2 matrix [[a, b], [c, d]] closer to d.
+func wilkinson(a, b, c, d complex128) complex128 {
+	tr, det := a+d, a*d-b*c
+	disc := cmplx.Sqrt(tr*tr/4 - det)
+
+	l0, l1 := tr/2+disc, tr/2-disc
+	if cmplx.Abs(l0-d) < cmplx.Abs(l1-d) {
+		return l0
+	}
+
+	return l1
+}
@@ -0,0 +1,265 @@
+package eigen_test
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/itsubaki/q/math/eigen"
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+func ExampleQR() {
+	// the eigenvalues of the unitary matrix are on the unit circle.
+	s := gate.S()
+
+	_, d, conv := eigen.QR(s, 100)
+	for i := range d.Rows {
+		fmt.Printf("%.4f\n", d.At(i, i))
+	}
+	fmt.Println(conv.Converged)
+
+	// Output:
+	// (1.0000+0.0000i)
+	// (0.0000+1.0000i)
+	// true
+}
+
+func ExampleSchur() {
+	x := matrix.New(
+		[]complex128{0, -1},
+		[]complex128{1, 0},
+	)
+
+	q, t, conv := eigen.Schur(x, 100)
+	fmt.Println(q.IsUnitary())
+	fmt.Println(matrix.MatMul(q, t, q.Dagger()).Equal(x))
+	fmt.Println(epsilon.IsZero(t.At(1, 0)))
+	fmt.Println(conv.Converged)
+
+	// Output:
+	// true
+	// true
+	// true
+	// true
+}
+
+func TestQR(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.H()},
+		{gate.RZ(math.Pi / 3)},
+		{gate.QFT(3)},
+		{
+			matrix.New(
+				[]complex128{0, -1},
+				[]complex128{1, 0},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{2, 1 + 2i, -1i},
+				[]complex128{1 - 2i, 3, 4 + 1i},
+				[]complex128{1i, 4 - 1i, -1},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 2, 3, 4},
+				[]complex128{0, 5, 6i, 7},
+				[]complex128{8, 9, 1, 2 - 1i},
+				[]complex128{3, 1i, 4, 1},
+			),
+		},
+	}
+
+	for _, c := range cases {
+		v, d, conv := eigen.QR(c.in, 1000)
+		if !conv.Converged {
+			t.Errorf("not converged: %+v", conv)
+		}
+
+		// a v = v d
+		if !matrix.MatMul(c.in, v).Equal(matrix.MatMul(v, d), 1e-6) {
+			t.Errorf("av=%v, vd=%v", matrix.MatMul(c.in, v), matrix.MatMul(v, d))
+		}
+
+		var trace complex128
+		for i := range d.Rows {
+			trace += d.At(i, i)
+		}
+
+		if cmplx.Abs(trace-c.in.Trace()) > 1e-8 {
+			t.Errorf("got=%v, want=%v", trace, c.in.Trace())
+		}
+	}
+}
+
+func TestQR_unitary(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.QFT(2)},
+		{gate.QFT(3)},
+		{gate.CNOT(2, 0, 1)},
+		{gate.U(1, 2, 3)},
+	}
+
+	for _, c := range cases {
+		_, d, _ := eigen.QR(c.in, 1000)
+		for i := range d.Rows {
+			if math.Abs(cmplx.Abs(d.At(i, i))-1) > 1e-8 {
+				t.Errorf("got=%v", d.At(i, i))
+			}
+		}
+	}
+}
+
+func TestQR_degenerate(t *testing.T) {
+	// the unitary matrix with the spectrum {1, 1, i, i, -1, e^0.3i}.
+	unitary := func() *matrix.Matrix {
+		r := rand.Const(1)
+		x := matrix.Zero(6, 6)
+		for i := range x.Data {
+			x.Data[i] = complex(r(), r())
+		}
+
+		q, _ := matrix.QR(x)
+		d := matrix.Zero(6, 6)
+		for i, l := range []complex128{1, 1, 1i, 1i, -1, cmplx.Exp(0.3i)} {
+			d.Set(i, i, l)
+		}
+
+		return matrix.MatMul(q, d, q.Dagger())
+	}
+
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.QFT(3)},
+		{matrix.MatMul(gate.CZ(2, 0, 1), gate.H(2))},
+		{gate.CNOT(2, 0, 1)},
+		{matrix.Identity(3)},
+		{unitary()},
+	}
+
+	for _, c := range cases {
+		v, d, conv := eigen.QR(c.in, 1000)
+		if !conv.Converged {
+			t.Errorf("not converged: %+v", conv)
+		}
+
+		// a v = v d
+		if !matrix.MatMul(c.in, v).Equal(matrix.MatMul(v, d), 1e-12) {
+			t.Errorf("av=%v, vd=%v", matrix.MatMul(c.in, v), matrix.MatMul(v, d))
+		}
+
+		// the eigenvectors are linearly independent.
+		if _, err := matrix.Inverse(v); err != nil {
+			t.Errorf("v=%v: %v", v, err)
+		}
+	}
+}
+
+func TestSchur(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.QFT(3)},
+		{
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{0, 1},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 2, 3, 4},
+				[]complex128{0, 5, 6i, 7},
+				[]complex128{8, 9, 1, 2 - 1i},
+				[]complex128{3, 1i, 4, 1},
+			),
+		},
+		{matrix.Zero(3, 3)},
+	}
+
+	for _, c := range cases {
+		q, tr, conv := eigen.Schur(c.in, 1000)
+		if !conv.Converged {
+			t.Errorf("not converged: %+v", conv)
+		}
+
+		if !q.IsUnitary() {
+			t.Errorf("q=%v is not unitary", q)
+		}
+
+		for i := range tr.Rows {
+			for j := range i {
+				if tr.At(i, j) != 0 {
+					t.Errorf("t=%v is not upper triangular", tr)
+				}
+			}
+		}
+
+		if !matrix.MatMul(q, tr, q.Dagger()).Equal(c.in) {
+			t.Errorf("got=%v, want=%v", matrix.MatMul(q, tr, q.Dagger()), c.in)
+		}
+	}
+}
+
+func TestSchur_iter(t *testing.T) {
+	x := gate.QFT(3)
+
+	_, _, conv := eigen.Schur(x, 0)
+	if conv.Converged {
+		t.Errorf("converged")
+	}
+
+	if conv.Iterations != 0 {
+		t.Errorf("got=%v, want=0", conv.Iterations)
+	}
+
+	if conv.Residual == 0 {
+		t.Errorf("residual is zero")
+	}
+}
+
+func TestHessenberg(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.QFT(3)},
+		{
+			matrix.New(
+				[]complex128{1, 2, 3, 4},
+				[]complex128{0, 5, 6i, 7},
+				[]complex128{8, 9, 1, 2 - 1i},
+				[]complex128{3, 1i, 4, 1},
+			),
+		},
+	}
+
+	for _, c := range cases {
+		q, h := eigen.Hessenberg(c.in)
+		if !q.IsUnitary() {
+			t.Errorf("q=%v is not unitary", q)
+		}
+
+		for i := range h.Rows {
+			for j := range i - 1 {
+				if h.At(i, j) != 0 {
+					t.Errorf("h=%v is not upper Hessenberg", h)
+				}
+			}
+		}
+
+		if !matrix.MatMul(q, h, q.Dagger()).Equal(c.in) {
+			t.Errorf("got=%v, want=%v", matrix.MatMul(q, h, q.Dagger()), c.in)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package funm
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/itsubaki/q/math/eigen"
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+)
+
+// pade is the coefficients of the [13/13] Pade approximant of the exponential.
+var pade = []float64{
+	64764752532480000, 32382376266240000, 7771770303897600, 1187353796428800,
+	129060195264000, 10559470521600, 670442572800, 33522128640,
+	1323241920, 40840800, 960960, 16380, 182, 1,
+}
+
+// Exp returns the matrix exponential of a.
+// If a is hermitian or anti-hermitian such as -iHt, it is computed by the diagonalization.
+// Otherwise, it is computed by the scaling and squaring method with the Pade approximant.
+// It returns an error if the denominator of the Pade approximant is singular.
+func Exp(a *matrix.Matrix, tol ...float64) (*matrix.Matrix, error) {
+	if a.IsHermitian(tol...) {
+		v, lambda := hermitian(a)
+		return compose(v, lambda, cmplx.Exp), nil
+	}
+
+	if h := a.Mul(-1i); h.IsHermitian(tol...) {
+		// exp(a) = exp(i * h), where h = -i * a is hermitian.
+		v, lambda := hermitian(h)
+		return compose(v, lambda, func(x complex128) complex128 { return cmplx.Exp(1i * x) }), nil
+	}
+
+	return expm(a)
+}
+
+// Log returns the principal matrix logarithm of a.
+// If a is normal such as a unitary matrix, it is computed by the diagonalization.
+// Otherwise, it is computed by the inverse scaling and squaring method on the Schur form.
+// The eigenvalues on the negative real axis are mapped to the imaginary part pi as cmplx.Log.
+// It returns an error if a is singular.
+func Log(a *matrix.Matrix, tol ...float64) (*matrix.Matrix, error) {
+	v, lambda, ok := normal(a, tol...)
+	if !ok {
+		return logm(a, tol...)
+	}
+
+	if singular(lambda, tol...) {
+		return nil, fmt.Errorf("singular matrix")
+	}
+
+	return compose(v, lambda, cmplx.Log), nil
+}
+
+// Pow returns the principal power a^p.
+// If a is normal such as a unitary matrix, it is computed by the diagonalization.
+// Otherwise, the integer power is computed by the repeated multiplication,
+// and the others are computed by the Schur form or exp(p * log(a)).
+// It returns an error if a is singular and either p is not positive or a is not normal and p is not an integer.
+func Pow(a *matrix.Matrix, p float64, tol ...float64) (*matrix.Matrix, error) {
+	v, lambda, ok := normal(a, tol...)
+	if ok {
+		if p <= 0 && singular(lambda, tol...) {
+			return nil, fmt.Errorf("singular matrix")
+		}
+
+		return compose(v, lambda, func(x complex128) complex128 { return cmplx.Pow(x, complex(p, 0)) }), nil
+	}
+
+	if p == math.Trunc(p) && math.Abs(p) <= math.MaxInt32 {
+		if p >= 0 {
+			return powm(a, int(p)), nil
+		}
+
+		inv, err := matrix.Inverse(a, tol...)
+		if err != nil {
+			return nil, err
+		}
+
+		return powm(inv, int(-p)), nil
+	}
+
+	if p == 0.5 {
+		return sqrtm(a, tol...)
+	}
+
+	log, err := logm(a, tol...)
+	if err != nil {
+		return nil, err
+	}
+
+	return expm(log.Mul(complex(p, 0)))
+}
+
+// Sqrt returns the principal square root of a.
+func Sqrt(a *matrix.Matrix, tol ...float64) (*matrix.Matrix, error) {
+	return Pow(a, 0.5, tol...)
+}
+
+// normal returns the unitary matrix v and the eigenvalues of a such that a = v diag(lambda) v^dagger,
+// and false if a is not normal.
+func normal(a *matrix.Matrix, tol ...float64) (v *matrix.Matrix, lambda []complex128, ok bool) {
+	if a.IsHermitian(tol...) {
+		v, lambda := hermitian(a)
+		return v, lambda, true
+	}
+
+	// the Schur form of a normal matrix is diagonal.
+	q, t, conv := eigen.Schur(a, eigen.Iterations(a), tol...)
+	if !conv.Converged || !t.IsDiagonal(tol...) {
+		return nil, nil, false
+	}
+
+	lambda = make([]complex128, t.Rows)
+	for i := range t.Rows {
+		lambda[i] = t.At(i, i)
+	}
+
+	return q, lambda, true
+}
+
+// hermitian returns the eigenvectors and the real eigenvalues of the hermitian matrix a.
+func hermitian(a *matrix.Matrix) (v *matrix.Matrix, lambda []complex128) {
+	// diagonalize to the machine precision regardless of the tolerance of the hermitian check.
+	v, d := eigen.Jacobi(a, eigen.Iterations(a), 1e-15*max(norm1(a), 1))
+
+	lambda = make([]complex128, d.Rows)
+	for i := range d.Rows {
+		lambda[i] = complex(real(d.At(i, i)), 0)
+	}
+
+	return v, lambda
+}
+
+// compose returns v diag(f(lambda)) v^dagger.
+func compose(v *matrix.Matrix, lambda []complex128, f func(x complex128) complex128) *matrix.Matrix {
+	d := matrix.Zero(len(lambda), len(lambda))
+	for i, x := range lambda {
+		d.Set(i, i, f(x))
+	}
+
+	return matrix.MatMul(v, d, v.Dagger())
+}
+
+// singular returns true if any of the eigenvalues is zero.
+func singular(lambda []complex128, tol ...float64) bool {
+	for _, x := range lambda {
+		if epsilon.IsZero(x, tol...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expm returns the matrix exponential of a using the scaling and squaring method.
+func expm(a *matrix.Matrix) (*matrix.Matrix, error) {
+	// scale a so that the norm is less than theta13.
+	var s int
+	if norm := norm1(a); norm > 5.371920351148152 {
+		s = int(math.Ceil(math.Log2(norm / 5.371920351148152)))
+	}
+
+	x := a.Mul(complex(math.Pow(2, -float64(s)), 0))
+	n := x.Rows
+
+	id := matrix.Identity(n)
+	x2 := x.MatMul(x)
+	x4 := x2.MatMul(x2)
+	x6 := x4.MatMul(x2)
+
+	b := func(k int) complex128 { return complex(pade[k], 0) }
+	u := x6.MatMul(x6.Mul(b(13)).Add(x4.Mul(b(11))).Add(x2.Mul(b(9))))
+	u = u.Add(x6.Mul(b(7))).Add(x4.Mul(b(5))).Add(x2.Mul(b(3))).Add(id.Mul(b(1)))
+	u = x.MatMul(u)
+
+	v := x6.MatMul(x6.Mul(b(12)).Add(x4.Mul(b(10))).Add(x2.Mul(b(8))))
+	v = v.Add(x6.Mul(b(6))).Add(x4.Mul(b(4))).Add(x2.Mul(b(2))).Add(id.Mul(b(0)))
+
+	// solve (v - u) r = (v + u). v - u is nonsingular for the scaled a.
+	inv, err := matrix.Inverse(v.Sub(u))
+	if err != nil {
+		return nil, err
+	}
+
+	r := inv.MatMul(v.Add(u))
+	for range s {
+		r = r.MatMul(r)
+	}
+
+	return r, nil
+}
+
+// logm returns the principal matrix logarithm of a using the inverse scaling and squaring method on the Schur form.
+func logm(a *matrix.Matrix, tol ...float64) (*matrix.Matrix, error) {
+	q, t, err := schur(a, tol...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range t.Rows {
+		if epsilon.IsZero(t.At(i, i), tol...) {
+			return nil, fmt.Errorf("singular matrix")
+		}
+	}
+
+	n := t.Rows
+	id := matrix.Identity(n)
+
+	// take the square roots until t is close to the identity.
+	k := 0
+	for ; norm1(t.Sub(id)) > 0.25 && k < 64; k++ {
+		r, err := sqrtt(t)
+		if err != nil {
+			return nil, err
+		}
+
+		t = r
+	}
+
+	// log(I + y) = y - y^2/2 + y^3/3 - ...
+	y := t.Sub(id)
+	out, term := matrix.Zero(n, n), id
+	for j := 1; j < 100; j++ {
+		term = term.MatMul(y)
+
+		sign := 1.0
+		if j%2 == 0 {
+			sign = -1
+		}
+
+		out = out.Add(term.Mul(complex(sign/float64(j), 0)))
+		if norm1(term)/float64(j) < 1e-17 {
+			break
+		}
+	}
+
+	out = out.Mul(complex(math.Pow(2, float64(k)), 0))
+	return matrix.MatMul(q, out, q.Dagger()), nil
+}
+
+// sqrtm returns the principal square root of a using the Schur form.
+func sqrtm(a *matrix.Matrix, tol ...float64) (*matrix.Matrix, error) {
+	q, t, err := schur(a, tol...)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := sqrtt(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return matrix.MatMul(q, u, q.Dagger()), nil
+}
+
+// sqrtt returns the principal square root of the upper triangular matrix t
+// using the Bjorck-Hammarling recurrence.
+func sqrtt(t *matrix.Matrix) (*matrix.Matrix, error) {
+	n := t.Rows
+	u := matrix.Zero(n, n)
+	for i := range n {
+		u.Set(i, i, cmplx.Sqrt(t.At(i, i)))
+	}
+
+	for d := 1; d < n; d++ {
+		for i := 0; i+d < n; i++ {
+			j := i + d
+
+			sum := t.At(i, j)
+			for k := i + 1; k < j; k++ {
+				sum -= u.At(i, k) * u.At(k, j)
+			}
+
+			den := u.At(i, i) + u.At(j, j)
+			if den == 0 {
+				if sum != 0 {
+					return nil, fmt.Errorf("singular matrix")
+				}
+
+				continue
+			}
+
+			u.Set(i, j, sum/den)
+		}
+	}
+
+	return u, nil
+}
+
+// schur returns the Schur decomposition of a, and an error if it does not converge.
+func schur(a *matrix.Matrix, tol ...float64) (q, t *matrix.Matrix, err error) {
+	q, t, conv := eigen.Schur(a, eigen.Iterations(a), tol...)
+	if !conv.Converged {
+		return nil, nil, fmt.Errorf("schur decomposition not converged: residual=%v", conv.Residual)
+	}
+
+	return q, t, nil
+}
+
+// powm returns a^p for the non-negative integer p using the repeated squaring.
+func powm(a *matrix.Matrix, p int) *matrix.Matrix {
+	out := matrix.Identity(a.Rows)
+	for x := a; p > 0; p >>= 1 {
+		if p&1 == 1 {
+			out = out.MatMul(x)
+		}
+
+		if p > 1 {
+			x = x.MatMul(x)
+		}
+	}
+
+	return out
+}
+
+// norm1 returns the maximum absolute column sum of a.
+func norm1(a *matrix.Matrix) float64 {
+	var out float64
+	for j := range a.Cols {
+		var sum float64
+		for i := range a.Rows {
+			sum += cmplx.Abs(a.At(i, j))
+		}
+
+		out = max(out, sum)
+	}
+
+	return out
+}
@@ -0,0 +1,400 @@
+package funm_test
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/funm"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+func ExampleExp() {
+	// exp(-i * theta/2 * X) = RX(theta)
+	theta := math.Pi / 3
+	h := gate.X().Mul(complex(theta/2, 0))
+
+	u, err := funm.Exp(h.Mul(-1i))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(u.Equal(gate.RX(theta)))
+
+	// Output:
+	// true
+}
+
+func ExamplePow() {
+	sqrtx, err := funm.Pow(gate.X(), 0.5)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, row := range sqrtx.Seq2() {
+		fmt.Printf("%.3f\n", row)
+	}
+	fmt.Println(matrix.MatMul(sqrtx, sqrtx).Equal(gate.X()))
+
+	// Output:
+	// [(0.500+0.500i) (0.500-0.500i)]
+	// [(0.500-0.500i) (0.500+0.500i)]
+	// true
+}
+
+func TestExp(t *testing.T) {
+	cases := []struct {
+		in   *matrix.Matrix
+		want *matrix.Matrix
+	}{
+		{
+			matrix.Zero(2, 2),
+			gate.I(),
+		},
+		{
+			gate.Z().Mul(-1i * math.Pi / 8),
+			gate.RZ(math.Pi / 4),
+		},
+		{
+			gate.Y().Mul(-1i * math.Pi / 4),
+			gate.RY(math.Pi / 2),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 0},
+				[]complex128{0, 2},
+			),
+			matrix.New(
+				[]complex128{math.E, 0},
+				[]complex128{0, math.E * math.E},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 0},
+			),
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{0, 1},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{0, 1},
+			),
+			matrix.New(
+				[]complex128{math.E, math.E},
+				[]complex128{0, math.E},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{0, 10},
+				[]complex128{0, 0},
+			),
+			matrix.New(
+				[]complex128{1, 10},
+				[]complex128{0, 1},
+			),
+		},
+	}
+
+	for _, c := range cases {
+		got, err := funm.Exp(c.in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !got.Equal(c.want) {
+			t.Errorf("got=%v, want=%v", got, c.want)
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.I()},
+		{gate.X()},
+		{gate.S()},
+		{gate.QFT(2)},
+		{gate.CNOT(2, 0, 1)},
+		{gate.U(1, 2, 3)},
+		{
+			matrix.New(
+				[]complex128{2, 1},
+				[]complex128{1, 3},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{0, 1},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{4, 1, 0},
+				[]complex128{0, 2i, 1},
+				[]complex128{1, 0, 3},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{-1, 1},
+				[]complex128{0, 2},
+			),
+		},
+	}
+
+	for _, c := range cases {
+		log, err := funm.Log(c.in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, err := funm.Exp(log); err != nil || !got.Equal(c.in) {
+			t.Errorf("got=%v, want=%v", got, c.in)
+		}
+	}
+}
+
+func TestLog_singular(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{matrix.Zero(2, 2)},
+		{
+			matrix.New(
+				[]complex128{1, 0},
+				[]complex128{0, 0},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 0},
+			),
+		},
+	}
+
+	for _, c := range cases {
+		if _, err := funm.Log(c.in); err == nil {
+			t.Errorf("expected error")
+		}
+	}
+}
+
+func TestPow(t *testing.T) {
+	cases := []struct {
+		in   *matrix.Matrix
+		p    float64
+		want *matrix.Matrix
+	}{
+		{gate.Z(), 0.5, gate.S()},
+		{gate.S(), 0.5, gate.T()},
+		{gate.T(), 2, gate.S()},
+		{gate.H(), 2, gate.I()},
+		{gate.X(), 0, gate.I()},
+		{gate.S(), -1, gate.S().Dagger()},
+		{
+			matrix.New(
+				[]complex128{1, 0},
+				[]complex128{0, 0},
+			),
+			0.5,
+			matrix.New(
+				[]complex128{1, 0},
+				[]complex128{0, 0},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{4, 4},
+				[]complex128{0, 4},
+			),
+			0.5,
+			matrix.New(
+				[]complex128{2, 1},
+				[]complex128{0, 2},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{0, 1},
+			),
+			3,
+			matrix.New(
+				[]complex128{1, 3},
+				[]complex128{0, 1},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{0, 1},
+			),
+			-2,
+			matrix.New(
+				[]complex128{1, -2},
+				[]complex128{0, 1},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 0},
+			),
+			2,
+			matrix.Zero(2, 2),
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 0},
+			),
+			0,
+			gate.I(),
+		},
+		{
+			matrix.New(
+				[]complex128{-1, 1},
+				[]complex128{0, 2},
+			),
+			2,
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{0, 4},
+			),
+		},
+	}
+
+	for _, c := range cases {
+		got, err := funm.Pow(c.in, c.p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !got.Equal(c.want) {
+			t.Errorf("got=%v, want=%v", got, c.want)
+		}
+	}
+}
+
+func TestPow_singular(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+		p  float64
+	}{
+		{matrix.Zero(2, 2), -1},
+		{matrix.Zero(2, 2), 0},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 0},
+			),
+			0.5,
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 0},
+			),
+			-1,
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 0},
+			),
+			1.5,
+		},
+	}
+
+	for _, c := range cases {
+		if _, err := funm.Pow(c.in, c.p); err == nil {
+			t.Errorf("expected error")
+		}
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.X()},
+		{gate.H()},
+		{gate.QFT(3)},
+		{
+			matrix.New(
+				[]complex128{0.5, 0.5},
+				[]complex128{0.5, 0.5},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{4, 1, 0},
+				[]complex128{0, 2i, 1},
+				[]complex128{1, 0, 3},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{-1, 1},
+				[]complex128{0, 2},
+			),
+		},
+	}
+
+	for _, c := range cases {
+		got, err := funm.Sqrt(c.in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !matrix.MatMul(got, got).Equal(c.in) {
+			t.Errorf("got=%v, want=%v", matrix.MatMul(got, got), c.in)
+		}
+	}
+}
+
+func TestPow_negative(t *testing.T) {
+	// the eigenvalue -1 is mapped to i as cmplx.Pow.
+	a := matrix.New(
+		[]complex128{-1, 1},
+		[]complex128{0, 2},
+	)
+
+	cases := []struct {
+		p float64
+		n int
+	}{
+		{0.5, 2},
+		{0.25, 4},
+		{1.0 / 3, 3},
+	}
+
+	for _, c := range cases {
+		got, err := funm.Pow(a, c.p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !matrix.ApplyN(got, c.n).Equal(a) {
+			t.Errorf("got=%v, want=%v", matrix.ApplyN(got, c.n), a)
+		}
+
+		if want := cmplx.Pow(-1, complex(c.p, 0)); !epsilon.IsClose(got.At(0, 0), want) {
+			t.Errorf("got=%v, want=%v", got.At(0, 0), want)
+		}
+	}
+}
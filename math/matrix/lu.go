@@ -0,0 +1,162 @@
+package matrix
+
+import (
+	"fmt"
+	"math/cmplx"
+
+	"github.com/itsubaki/q/math/epsilon"
+)
+
+// LU returns the LU decomposition p * a = l * u with the partial pivoting.
+// l is a unit lower triangular matrix, u is an upper triangular matrix and p is a permutation matrix.
+// It panics if a is not square.
+func LU(a *Matrix) (l, u, p *Matrix) {
+	if !a.IsSquare() {
+		panic(fmt.Sprintf("matrix: LU decomposition of non-square matrix %dx%d", a.Rows, a.Cols))
+	}
+
+	l, u, perm, _ := lu(a)
+
+	p = Zero(a.Rows, a.Rows)
+	for i, j := range perm {
+		p.Set(i, j, 1)
+	}
+
+	return l, u, p
+}
+
+// Det returns the determinant of a.
+// It returns zero if a is singular, that is, a pivot is not larger than the absolute tolerance times the 1-norm of a.
+// It panics if a is not square.
+func Det(a *Matrix, tol ...float64) complex128 {
+	if !a.IsSquare() {
+		panic(fmt.Sprintf("matrix: determinant of non-square matrix %dx%d", a.Rows, a.Cols))
+	}
+
+	_, u, _, swaps := lu(a)
+	if singular(a, u, tol...) {
+		return 0
+	}
+
+	det := complex(1, 0)
+	if swaps%2 == 1 {
+		det = -1
+	}
+
+	for i := range u.Rows {
+		det *= u.At(i, i)
+	}
+
+	return det
+}
+
+// Inverse returns the inverse of a.
+// It returns an error if a is not square or singular,
+// that is, a pivot is not larger than the absolute tolerance times the 1-norm of a.
+func Inverse(a *Matrix, tol ...float64) (*Matrix, error) {
+	if !a.IsSquare() {
+		return nil, fmt.Errorf("non-square matrix %dx%d", a.Rows, a.Cols)
+	}
+
+	l, u, perm, _ := lu(a)
+	if singular(a, u, tol...) {
+		return nil, fmt.Errorf("singular matrix")
+	}
+
+	n := a.Rows
+	// solve l * u * x = p * e_j for each column j.
+	out := Zero(n, n)
+	for j := range n {
+		y := make([]complex128, n)
+		for i := range n {
+			if perm[i] == j {
+				y[i] = 1
+			}
+
+			for k := range i {
+				y[i] -= l.At(i, k) * y[k]
+			}
+		}
+
+		for i := n - 1; i >= 0; i-- {
+			x := y[i]
+			for k := i + 1; k < n; k++ {
+				x -= u.At(i, k) * out.At(k, j)
+			}
+
+			out.Set(i, j, x/u.At(i, i))
+		}
+	}
+
+	return out, nil
+}
+
+// lu returns the LU decomposition with the permutation and the number of the row swaps.
+// The i-th row of p * a is the perm[i]-th row of a.
+func lu(a *Matrix) (l, u *Matrix, perm []int, swaps int) {
+	n := a.Rows
+	l, u = Identity(n), a.Clone()
+
+	perm = make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	for k := range n {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if cmplx.Abs(u.At(i, k)) > cmplx.Abs(u.At(p, k)) {
+				p = i
+			}
+		}
+
+		if p != k {
+			u = u.Swap(k, p)
+			perm[k], perm[p] = perm[p], perm[k]
+			swaps++
+
+			for j := range k {
+				lk, lp := l.At(k, j), l.At(p, j)
+				l.Set(k, j, lp)
+				l.Set(p, j, lk)
+			}
+		}
+
+		if u.At(k, k) == 0 {
+			continue
+		}
+
+		for i := k + 1; i < n; i++ {
+			f := u.At(i, k) / u.At(k, k)
+			l.Set(i, k, f)
+			for j := k; j < n; j++ {
+				u.SubAt(i, j, f*u.At(k, j))
+			}
+		}
+	}
+
+	return l, u, perm, swaps
+}
+
+// singular returns true if a pivot of u is not larger than the absolute tolerance times the 1-norm of a.
+func singular(a, u *Matrix, tol ...float64) bool {
+	atol, _ := epsilon.Tol(tol...)
+
+	var norm float64
+	for j := range a.Cols {
+		var sum float64
+		for i := range a.Rows {
+			sum += cmplx.Abs(a.At(i, j))
+		}
+
+		norm = max(norm, sum)
+	}
+
+	for i := range u.Rows {
+		if cmplx.Abs(u.At(i, i)) <= atol*norm {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,184 @@
+package matrix_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+)
+
+func ExampleLU() {
+	a := matrix.New(
+		[]complex128{1, 2},
+		[]complex128{3, 4},
+	)
+
+	l, u, p := matrix.LU(a)
+	fmt.Println(l.Real())
+	fmt.Println(u.Real())
+	fmt.Println(p.Real())
+
+	// Output:
+	// [[1 0] [0.3333333333333333 1]]
+	// [[3 4] [0 0.6666666666666667]]
+	// [[0 1] [1 0]]
+}
+
+func ExampleDet() {
+	a := matrix.New(
+		[]complex128{1, 2},
+		[]complex128{3, 4},
+	)
+
+	fmt.Println(matrix.Det(a))
+
+	// Output:
+	// (-2+0i)
+}
+
+func ExampleInverse() {
+	a := matrix.New(
+		[]complex128{1, 2},
+		[]complex128{3, 4},
+	)
+
+	inv, err := matrix.Inverse(a)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%.4f\n", inv.Real())
+	fmt.Println(matrix.MatMul(a, inv).IsIdentity())
+
+	// Output:
+	// [[-2.0000 1.0000] [1.5000 -0.5000]]
+	// true
+}
+
+func TestLU(t *testing.T) {
+	cases := []struct {
+		in  *matrix.Matrix
+		det complex128
+	}{
+		{
+			matrix.New(
+				[]complex128{1, 2},
+				[]complex128{3, 4},
+			),
+			-2,
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{1, 0},
+			),
+			-1,
+		},
+		{
+			matrix.New(
+				[]complex128{1i, 2, 0},
+				[]complex128{0, 1 - 1i, 3},
+				[]complex128{2, 0, 1i},
+			),
+			1i*((1-1i)*1i-0) - 2*(0-6) + 0,
+		},
+		{
+			matrix.New(
+				[]complex128{1, 2, 3},
+				[]complex128{2, 4, 6},
+				[]complex128{1, 0, 1},
+			),
+			0,
+		},
+		{
+			matrix.New(
+				[]complex128{1, 2},
+				[]complex128{3, 6 + 1e-15},
+			),
+			0,
+		},
+		{
+			matrix.Identity(4),
+			1,
+		},
+	}
+
+	for _, c := range cases {
+		l, u, p := matrix.LU(c.in)
+		if got, want := matrix.MatMul(l, u), matrix.MatMul(p, c.in); !got.Equal(want) {
+			t.Errorf("got=%v, want=%v", got, want)
+		}
+
+		for i := range l.Rows {
+			if l.At(i, i) != 1 {
+				t.Errorf("l=%v is not unit lower triangular", l)
+			}
+
+			for j := i + 1; j < l.Cols; j++ {
+				if l.At(i, j) != 0 || u.At(j, i) != 0 {
+					t.Errorf("l=%v, u=%v is not triangular", l, u)
+				}
+			}
+		}
+
+		if got := matrix.Det(c.in); !epsilon.IsClose(got, c.det) {
+			t.Errorf("got=%v, want=%v", got, c.det)
+		}
+
+		inv, err := matrix.Inverse(c.in)
+		if c.det == 0 {
+			if err == nil {
+				t.Errorf("err is nil")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !matrix.MatMul(c.in, inv).IsIdentity() || !matrix.MatMul(inv, c.in).IsIdentity() {
+			t.Errorf("inv=%v", inv)
+		}
+	}
+}
+
+func TestInverse_error(t *testing.T) {
+	cases := []struct {
+		in   *matrix.Matrix
+		tol  []float64
+		want string
+	}{
+		{matrix.Zero(2, 3), nil, "non-square matrix 2x3"},
+		{matrix.New([]complex128{1e-17, 0}, []complex128{0, 1}), nil, "singular matrix"},
+		{matrix.New([]complex128{1e-3, 0}, []complex128{0, 1}), []float64{1e-2}, "singular matrix"},
+	}
+
+	for _, c := range cases {
+		if _, err := matrix.Inverse(c.in, c.tol...); err == nil || err.Error() != c.want {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+	}
+}
+
+func TestDet_panic(t *testing.T) {
+	defer func() {
+		if rec := recover(); rec != "matrix: determinant of non-square matrix 2x3" {
+			t.Errorf("got=%v", rec)
+		}
+	}()
+
+	matrix.Det(matrix.Zero(2, 3))
+}
+
+func TestLU_panic(t *testing.T) {
+	defer func() {
+		if rec := recover(); rec != "matrix: LU decomposition of non-square matrix 3x2" {
+			t.Errorf("got=%v", rec)
+		}
+	}()
+
+	matrix.LU(matrix.Zero(3, 2))
+}
@@ -154,7 +154,7 @@ func (m *Matrix) Conjugate() *Matrix {
 
 // Dagger returns the conjugate transpose of m.
 func (m *Matrix) Dagger() *Matrix {
-	out := ZeroLike(m)
+	out := Zero(m.Cols, m.Rows)
 	for i := range m.Rows {
 		for j := range m.Cols {
 			out.Set(j, i, cmplx.Conj(m.At(i, j)))
@@ -442,6 +442,11 @@ func TestMatrix_Dagger(t *testing.T) {
 				[]complex128{4 + 5i, 6 + 7i},
 			),
 		},
+		{
+			matrix.New(
+				[]complex128{1 + 1i, 2 + 3i, 4 + 5i},
+			),
+		},
 	}
 
 	for _, c := range cases {
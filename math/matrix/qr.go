@@ -0,0 +1,79 @@
+package matrix
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// QR returns the QR decomposition a = q * r using the Householder reflections.
+// q is a unitary matrix of rows x rows, and r is an upper triangular matrix of the same size as a.
+func QR(a *Matrix) (q *Matrix, r *Matrix) {
+	m, n := a.Dim()
+	q, r = Identity(m), a.Clone()
+
+	for k := range min(m-1, n) {
+		// v is the Householder vector that maps r[k:, k] to alpha * e_k.
+		var norm float64
+		for i := k; i < m; i++ {
+			norm += real(r.At(i, k) * cmplx.Conj(r.At(i, k)))
+		}
+
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+
+		phase := complex(1, 0)
+		if x := r.At(k, k); x != 0 {
+			phase = x / complex(cmplx.Abs(x), 0)
+		}
+
+		v := make([]complex128, m)
+		for i := k; i < m; i++ {
+			v[i] = r.At(i, k)
+		}
+		v[k] += phase * complex(norm, 0)
+
+		var vnorm float64
+		for i := k; i < m; i++ {
+			vnorm += real(v[i] * cmplx.Conj(v[i]))
+		}
+
+		if vnorm == 0 {
+			continue
+		}
+
+		// r = (I - 2vv^dagger/|v|^2) * r
+		for j := range n {
+			var w complex128
+			for i := k; i < m; i++ {
+				w += cmplx.Conj(v[i]) * r.At(i, j)
+			}
+
+			w *= complex(2/vnorm, 0)
+			for i := k; i < m; i++ {
+				r.SubAt(i, j, v[i]*w)
+			}
+		}
+
+		// q = q * (I - 2vv^dagger/|v|^2)
+		for i := range m {
+			var w complex128
+			for l := k; l < m; l++ {
+				w += q.At(i, l) * v[l]
+			}
+
+			w *= complex(2/vnorm, 0)
+			for l := k; l < m; l++ {
+				q.SubAt(i, l, w*cmplx.Conj(v[l]))
+			}
+		}
+
+		// the elements below the diagonal are zero.
+		for i := k + 1; i < m; i++ {
+			r.Set(i, k, 0)
+		}
+	}
+
+	return q, r
+}
@@ -0,0 +1,87 @@
+package matrix_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q/math/matrix"
+)
+
+func ExampleQR() {
+	a := matrix.New(
+		[]complex128{3, 1},
+		[]complex128{4, 2},
+	)
+
+	q, r := matrix.QR(a)
+	fmt.Println(q.IsUnitary())
+	fmt.Println(matrix.MatMul(q, r).Equal(a))
+	fmt.Printf("%.4f\n", r.Real())
+
+	// Output:
+	// true
+	// true
+	// [[-5.0000 -2.2000] [0.0000 0.4000]]
+}
+
+func TestQR(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{
+			matrix.New(
+				[]complex128{1, 2},
+				[]complex128{3, 4},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1i, 2, 0},
+				[]complex128{0, 1 - 1i, 3},
+				[]complex128{2, 0, 1i},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 0},
+				[]complex128{0, 1i},
+				[]complex128{1, 1},
+				[]complex128{0.5, -2},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 2, 3},
+				[]complex128{4, 5, 6i},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{0, 1},
+				[]complex128{0, 1},
+			),
+		},
+		{
+			matrix.Zero(3, 3),
+		},
+	}
+
+	for _, c := range cases {
+		q, r := matrix.QR(c.in)
+		if !q.IsUnitary() {
+			t.Errorf("q=%v is not unitary", q)
+		}
+
+		for i := range r.Rows {
+			for j := range min(i, r.Cols) {
+				if r.At(i, j) != 0 {
+					t.Errorf("r=%v is not upper triangular", r)
+				}
+			}
+		}
+
+		if got := matrix.MatMul(q, r); !got.Equal(c.in) {
+			t.Errorf("got=%v, want=%v", got, c.in)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package matrix
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// SVD returns the singular value decomposition a = u * diag(s) * v^dagger
+// using the one-sided Jacobi method.
+// The singular values are in descending order, and u and v have min(rows, cols) orthonormal columns.
+// The left singular vectors of the zero singular values are completed with the Gram-Schmidt process.
+func SVD(a *Matrix) (*Matrix, []float64, *Matrix) {
+	if a.Rows < a.Cols {
+		v, s, u := SVD(a.Dagger())
+		return u, s, v
+	}
+
+	m, n := a.Dim()
+	u, v := a.Clone(), Identity(n)
+
+	for range 100 {
+		var rotated bool
+		for p := range n - 1 {
+			for q := p + 1; q < n; q++ {
+				var alpha, beta float64
+				var gamma complex128
+				for i := range m {
+					up, uq := u.At(i, p), u.At(i, q)
+					alpha += real(up * cmplx.Conj(up))
+					beta += real(uq * cmplx.Conj(uq))
+					gamma += cmplx.Conj(up) * uq
+				}
+
+				g := cmplx.Abs(gamma)
+				if g <= 1e-15*math.Sqrt(alpha*beta) || g < 1e-300 {
+					continue
+				}
+				rotated = true
+
+				// make gamma real and apply the real rotation.
+				phase := cmplx.Conj(gamma) / complex(g, 0)
+				zeta := (beta - alpha) / (2 * g)
+				t := 1 / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				if zeta < 0 {
+					t = -t
+				}
+
+				c := 1 / math.Sqrt(1+t*t)
+				cs, sn := complex(c, 0), complex(c*t, 0)
+
+				for _, w := range []*Matrix{u, v} {
+					for i := range w.Rows {
+						wp, wq := w.At(i, p), w.At(i, q)*phase
+						w.Set(i, p, cs*wp-sn*wq)
+						w.Set(i, q, sn*wp+cs*wq)
+					}
+				}
+			}
+		}
+
+		if !rotated {
+			break
+		}
+	}
+
+	// the singular values are the norms of the columns of u.
+	s := make([]float64, n)
+	for j := range n {
+		var norm float64
+		for i := range m {
+			norm += real(u.At(i, j) * cmplx.Conj(u.At(i, j)))
+		}
+
+		s[j] = math.Sqrt(norm)
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return s[order[i]] > s[order[j]] })
+
+	uu, vv, ss := Zero(m, n), Zero(n, n), make([]float64, n)
+	for k, j := range order {
+		ss[k] = s[j]
+		for i := range m {
+			if s[j] > 0 {
+				uu.Set(i, k, u.At(i, j)/complex(s[j], 0))
+			}
+		}
+
+		for i := range n {
+			vv.Set(i, k, v.At(i, j))
+		}
+	}
+
+	for k := range n {
+		if ss[k] > 1e-14*ss[0] {
+			continue
+		}
+
+		complete(uu, k)
+	}
+
+	return uu, ss, vv
+}
+
+// complete sets the k-th column of u to a unit vector orthogonal to the first k columns.
+// It takes the standard basis vector with the largest component orthogonal to the first k columns.
+func complete(u *Matrix, k int) {
+	var best []complex128
+	var bestNorm float64
+	for e := range u.Rows {
+		w := make([]complex128, u.Rows)
+		w[e] = 1
+
+		// orthogonalize twice for the numerical stability.
+		for range 2 {
+			for j := range k {
+				var dot complex128
+				for i := range u.Rows {
+					dot += cmplx.Conj(u.At(i, j)) * w[i]
+				}
+
+				for i := range u.Rows {
+					w[i] -= dot * u.At(i, j)
+				}
+			}
+		}
+
+		var norm float64
+		for i := range w {
+			norm += real(w[i] * cmplx.Conj(w[i]))
+		}
+
+		if norm > bestNorm {
+			best, bestNorm = w, norm
+		}
+	}
+
+	norm := complex(math.Sqrt(bestNorm), 0)
+	for i := range best {
+		u.Set(i, k, best[i]/norm)
+	}
+}
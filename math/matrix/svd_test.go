@@ -0,0 +1,111 @@
+package matrix_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q/math/matrix"
+)
+
+func ExampleSVD() {
+	a := matrix.New(
+		[]complex128{3, 0},
+		[]complex128{0, -2},
+		[]complex128{0, 0},
+	)
+
+	u, s, v := matrix.SVD(a)
+	fmt.Println(s)
+	fmt.Println(u.Dim())
+	fmt.Println(v.Dim())
+
+	// Output:
+	// [3 2]
+	// 3 2
+	// 2 2
+}
+
+func TestSVD(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{
+			matrix.New(
+				[]complex128{1, 2},
+				[]complex128{3, 4},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 1i, 0},
+				[]complex128{0, 2, 1 - 1i},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 0},
+				[]complex128{0, 1i},
+				[]complex128{1, 1},
+				[]complex128{0.5, -2},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 1},
+				[]complex128{1, 1},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{1, 2},
+				[]complex128{2, 4},
+				[]complex128{1i, 2i},
+			),
+		},
+		{
+			matrix.New(
+				[]complex128{0, 0, 0},
+				[]complex128{0, 1, 0},
+				[]complex128{0, 0, 0},
+			),
+		},
+		{
+			matrix.Zero(2, 3),
+		},
+		{
+			matrix.Zero(3, 2),
+		},
+	}
+
+	for _, c := range cases {
+		u, s, v := matrix.SVD(c.in)
+
+		k := min(c.in.Rows, c.in.Cols)
+		if len(s) != k || u.Cols != k || v.Cols != k {
+			t.Fatalf("len(s)=%v, u=%v, v=%v", len(s), u, v)
+		}
+
+		for i := 1; i < len(s); i++ {
+			if s[i-1] < s[i] {
+				t.Errorf("s=%v is not in descending order", s)
+			}
+		}
+
+		// a = u * diag(s) * v^dagger
+		d := matrix.Zero(k, k)
+		for i := range s {
+			d.Set(i, i, complex(s[i], 0))
+		}
+
+		if got := matrix.MatMul(u, d, v.Dagger()); !got.Equal(c.in) {
+			t.Errorf("got=%v, want=%v", got, c.in)
+		}
+
+		// the singular vectors are orthonormal including those of the zero singular values.
+		for _, w := range []*matrix.Matrix{u, v} {
+			if g := matrix.MatMul(w.Dagger(), w); !g.IsIdentity(1e-10) {
+				t.Errorf("got=%v, want identity", g)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package q
 
 import (
+	"maps"
 	"sort"
 
 	"github.com/itsubaki/q/math/matrix"
@@ -34,7 +35,10 @@ func Index(qb ...Qubit) []int {
 
 // Q is a quantum computing simulator.
 type Q struct {
-	qb *qubit.Qubit
+	qb    *qubit.Qubit
+	rec   *Circuit
+	bits  map[Qubit]int // the outcomes of the last measurements
+	clbit map[Qubit]int // the classical bits of the last recorded measurements
 }
 
 // New returns a new quantum computing simulator.
@@ -46,14 +50,26 @@ func New() *Q {
 
 // New appends a new qubit and returns its index.
 func (q *Q) New(v ...complex128) Qubit {
-	if q.NumQubits() == 0 {
+	n := q.NumQubits()
+	if n == 0 {
 		qb := qubit.New(vector.New(v...))
 		qb.SetRand(q.qb.Rand())
+		qb.SetWorkers(q.qb.Workers())
+		qb.SetThreshold(q.qb.Threshold())
 		q.qb = qb
-		return Qubit(0)
+	} else {
+		q.qb.TensorProduct(qubit.New(vector.New(v...)))
+	}
+
+	if q.rec != nil {
+		target := make([]Qubit, q.NumQubits()-n)
+		for i := range target {
+			target[i] = Qubit(n + i)
+		}
+
+		q.record(Op{Type: OpNew, Target: target, State: v})
 	}
 
-	q.qb.TensorProduct(qubit.New(vector.New(v...)))
 	return Qubit(q.NumQubits() - 1)
 }
 
@@ -62,6 +78,17 @@ func (q *Q) SetRand(rand func() float64) {
 	q.qb.SetRand(rand)
 }
 
+// SetWorkers sets the number of goroutines the gates and the measurement use.
+// If n < 2, they run serially.
+func (q *Q) SetWorkers(n int) {
+	q.qb.SetWorkers(n)
+}
+
+// SetThreshold sets the minimum number of qubits for the parallel execution.
+func (q *Q) SetThreshold(n int) {
+	q.qb.SetThreshold(n)
+}
+
 // Zero returns a qubit in the zero state.
 func (q *Q) Zero() Qubit {
 	return q.New(1, 0)
@@ -110,21 +137,35 @@ func (q *Q) Probability() []float64 {
 // Reset sets the given qubits to the zero state.
 func (q *Q) Reset(qb ...Qubit) {
 	for i := range qb {
-		if q.Measure(qb[i]).IsOne() {
-			q.X(qb[i])
+		q.record(Op{Type: OpReset, Target: []Qubit{qb[i]}})
+		if q.qb.Measure(qb[i].Index()).IsOne() {
+			q.qb.X(qb[i].Index())
 		}
 	}
 }
 
 // Apply applies a list of gates to the qubits.
 func (q *Q) Apply(g ...*matrix.Matrix) *Q {
+	for i := range g {
+		q.record(Op{Type: OpApply, Matrix: g[i]})
+	}
+
 	q.qb.Apply(g...)
 	return q
 }
 
+// ApplyAt applies a 2^k x 2^k gate to the k qubits.
+// The first qubit corresponds to the most significant bit of the gate.
+func (q *Q) ApplyAt(g *matrix.Matrix, qb ...Qubit) *Q {
+	q.record(Op{Type: OpApplyAt, Target: append([]Qubit(nil), qb...), Matrix: g})
+	q.qb.ApplyAt(g, Index(qb...)...)
+	return q
+}
+
 // G applies a gate.
 func (q *Q) G(g *matrix.Matrix, qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpG, Target: []Qubit{qb[i]}, Matrix: g})
 		q.qb.G(g, qb[i].Index())
 	}
 
@@ -134,6 +175,7 @@ func (q *Q) G(g *matrix.Matrix, qb ...Qubit) *Q {
 // U applies the U gate.
 func (q *Q) U(theta, phi, lambda float64, qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpU, Target: []Qubit{qb[i]}, Params: []float64{theta, phi, lambda}})
 		q.qb.U(theta, phi, lambda, qb[i].Index())
 	}
 
@@ -143,6 +185,7 @@ func (q *Q) U(theta, phi, lambda float64, qb ...Qubit) *Q {
 // I applies the I gate.
 func (q *Q) I(qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpI, Target: []Qubit{qb[i]}})
 		q.qb.I(qb[i].Index())
 	}
 
@@ -152,6 +195,7 @@ func (q *Q) I(qb ...Qubit) *Q {
 // X applies the X gate.
 func (q *Q) X(qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpX, Target: []Qubit{qb[i]}})
 		q.qb.X(qb[i].Index())
 	}
 
@@ -161,6 +205,7 @@ func (q *Q) X(qb ...Qubit) *Q {
 // Y applies the Y gate.
 func (q *Q) Y(qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpY, Target: []Qubit{qb[i]}})
 		q.qb.Y(qb[i].Index())
 	}
 
@@ -170,6 +215,7 @@ func (q *Q) Y(qb ...Qubit) *Q {
 // Z applies the Z gate.
 func (q *Q) Z(qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpZ, Target: []Qubit{qb[i]}})
 		q.qb.Z(qb[i].Index())
 	}
 
@@ -179,6 +225,7 @@ func (q *Q) Z(qb ...Qubit) *Q {
 // H applies the H gate.
 func (q *Q) H(qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpH, Target: []Qubit{qb[i]}})
 		q.qb.H(qb[i].Index())
 	}
 
@@ -188,6 +235,7 @@ func (q *Q) H(qb ...Qubit) *Q {
 // S applies the S gate.
 func (q *Q) S(qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpS, Target: []Qubit{qb[i]}})
 		q.qb.S(qb[i].Index())
 	}
 
@@ -197,6 +245,7 @@ func (q *Q) S(qb ...Qubit) *Q {
 // T applies the T gate.
 func (q *Q) T(qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpT, Target: []Qubit{qb[i]}})
 		q.qb.T(qb[i].Index())
 	}
 
@@ -206,6 +255,7 @@ func (q *Q) T(qb ...Qubit) *Q {
 // R applies the R gate with theta.
 func (q *Q) R(theta float64, qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpR, Target: []Qubit{qb[i]}, Params: []float64{theta}})
 		q.qb.R(theta, qb[i].Index())
 	}
 
@@ -215,6 +265,7 @@ func (q *Q) R(theta float64, qb ...Qubit) *Q {
 // RX applies the RX gate with theta.
 func (q *Q) RX(theta float64, qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpRX, Target: []Qubit{qb[i]}, Params: []float64{theta}})
 		q.qb.RX(theta, qb[i].Index())
 	}
 
@@ -224,6 +275,7 @@ func (q *Q) RX(theta float64, qb ...Qubit) *Q {
 // RY applies the RY gate with theta.
 func (q *Q) RY(theta float64, qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpRY, Target: []Qubit{qb[i]}, Params: []float64{theta}})
 		q.qb.RY(theta, qb[i].Index())
 	}
 
@@ -233,6 +285,7 @@ func (q *Q) RY(theta float64, qb ...Qubit) *Q {
 // RZ applies the RZ gate with theta.
 func (q *Q) RZ(theta float64, qb ...Qubit) *Q {
 	for i := range qb {
+		q.record(Op{Type: OpRZ, Target: []Qubit{qb[i]}, Params: []float64{theta}})
 		q.qb.RZ(theta, qb[i].Index())
 	}
 
@@ -287,6 +340,7 @@ func (q *Q) CR(theta float64, control, target Qubit) *Q {
 // Controlled applies a controlled operation with g.
 func (q *Q) Controlled(g *matrix.Matrix, control, target []Qubit) *Q {
 	for i := range target {
+		q.record(Op{Type: OpControlled, Control: control, Target: []Qubit{target[i]}, Matrix: g})
 		q.qb.Controlled(g, Index(control...), target[i].Index())
 	}
 
@@ -296,6 +350,7 @@ func (q *Q) Controlled(g *matrix.Matrix, control, target []Qubit) *Q {
 // ControlledU applies a controlled unitary operation.
 func (q *Q) ControlledU(theta, phi, lambda float64, control, target []Qubit) *Q {
 	for i := range target {
+		q.record(Op{Type: OpControlledU, Control: control, Target: []Qubit{target[i]}, Params: []float64{theta, phi, lambda}})
 		q.qb.ControlledU(theta, phi, lambda, Index(control...), target[i].Index())
 	}
 
@@ -305,6 +360,7 @@ func (q *Q) ControlledU(theta, phi, lambda float64, control, target []Qubit) *Q
 // ControlledH applies the controlled-Hadamard gate.
 func (q *Q) ControlledH(control, target []Qubit) *Q {
 	for i := range target {
+		q.record(Op{Type: OpControlledH, Control: control, Target: []Qubit{target[i]}})
 		q.qb.ControlledH(Index(control...), target[i].Index())
 	}
 
@@ -319,6 +375,7 @@ func (q *Q) ControlledX(control, target []Qubit) *Q {
 // ControlledNot applies the CNOT gate.
 func (q *Q) ControlledNot(control, target []Qubit) *Q {
 	for i := range target {
+		q.record(Op{Type: OpControlledX, Control: control, Target: []Qubit{target[i]}})
 		q.qb.ControlledX(Index(control...), target[i].Index())
 	}
 
@@ -328,6 +385,7 @@ func (q *Q) ControlledNot(control, target []Qubit) *Q {
 // ControlledZ applies the controlled-Z gate.
 func (q *Q) ControlledZ(control, target []Qubit) *Q {
 	for i := range target {
+		q.record(Op{Type: OpControlledZ, Control: control, Target: []Qubit{target[i]}})
 		q.qb.ControlledZ(Index(control...), target[i].Index())
 	}
 
@@ -337,6 +395,7 @@ func (q *Q) ControlledZ(control, target []Qubit) *Q {
 // ControlledR applies the controlled-R gate.
 func (q *Q) ControlledR(theta float64, control, target []Qubit) *Q {
 	for i := range target {
+		q.record(Op{Type: OpControlledR, Control: control, Target: []Qubit{target[i]}, Params: []float64{theta}})
 		q.qb.ControlledR(theta, Index(control...), target[i].Index())
 	}
 
@@ -344,6 +403,7 @@ func (q *Q) ControlledR(theta float64, control, target []Qubit) *Q {
 }
 
 // CondX applies the X gate if condition is true.
+// While recording, the gate is recorded only if it is applied. Use IfX to record the condition.
 func (q *Q) CondX(condition bool, qb ...Qubit) *Q {
 	if condition {
 		return q.X(qb...)
@@ -353,6 +413,7 @@ func (q *Q) CondX(condition bool, qb ...Qubit) *Q {
 }
 
 // CondZ applies the Z gate if condition is true.
+// While recording, the gate is recorded only if it is applied. Use IfZ to record the condition.
 func (q *Q) CondZ(condition bool, qb ...Qubit) *Q {
 	if condition {
 		return q.Z(qb...)
@@ -362,6 +423,7 @@ func (q *Q) CondZ(condition bool, qb ...Qubit) *Q {
 }
 
 // Cond applies g if condition is true.
+// While recording, g is recorded only if it is applied. Use If to record the condition.
 func (q *Q) Cond(condition bool, g *matrix.Matrix, qb ...Qubit) *Q {
 	if condition {
 		return q.G(g, qb...)
@@ -370,8 +432,42 @@ func (q *Q) Cond(condition bool, g *matrix.Matrix, qb ...Qubit) *Q {
 	return q
 }
 
+// IfX applies the X gate if the outcomes of the last measurements of m equal value,
+// where the outcome of m[i] is the i-th bit of value.
+// While recording, it is recorded as the X gate conditioned on the classical bits of the measurements.
+func (q *Q) IfX(m []Qubit, value int, qb ...Qubit) *Q {
+	for i := range qb {
+		q.cond(m, value, Op{Type: OpX, Target: []Qubit{qb[i]}})
+	}
+
+	return q
+}
+
+// IfZ applies the Z gate if the outcomes of the last measurements of m equal value,
+// where the outcome of m[i] is the i-th bit of value.
+// While recording, it is recorded as the Z gate conditioned on the classical bits of the measurements.
+func (q *Q) IfZ(m []Qubit, value int, qb ...Qubit) *Q {
+	for i := range qb {
+		q.cond(m, value, Op{Type: OpZ, Target: []Qubit{qb[i]}})
+	}
+
+	return q
+}
+
+// If applies g if the outcomes of the last measurements of m equal value,
+// where the outcome of m[i] is the i-th bit of value.
+// While recording, it is recorded as g conditioned on the classical bits of the measurements.
+func (q *Q) If(m []Qubit, value int, g *matrix.Matrix, qb ...Qubit) *Q {
+	for i := range qb {
+		q.cond(m, value, Op{Type: OpG, Target: []Qubit{qb[i]}, Matrix: g})
+	}
+
+	return q
+}
+
 // Swap applies the swap gate.
 func (q *Q) Swap(qb0, qb1 Qubit) *Q {
+	q.record(Op{Type: OpSwap, Target: []Qubit{qb0, qb1}})
 	q.qb.Swap(qb0.Index(), qb1.Index())
 	return q
 }
@@ -390,18 +486,61 @@ func (q *Q) Measure(qb ...Qubit) *qubit.Qubit {
 		}
 	}
 
+	if q.bits == nil {
+		q.bits = make(map[Qubit]int)
+	}
+
 	m := make([]*qubit.Qubit, len(qb))
 	for i := range qb {
+		q.recordMeasure(qb[i])
 		m[i] = q.qb.Measure(qb[i].Index())
+
+		q.bits[qb[i]] = 0
+		if m[i].IsOne() {
+			q.bits[qb[i]] = 1
+		}
 	}
 
 	return qubit.TensorProduct(m...)
 }
 
+// Sample returns the counts of shots drawn from the probability distribution of the given qubits.
+// If no qubits are given, it samples all qubits. The state of q is not changed.
+func (q *Q) Sample(shots int, qb ...Qubit) qubit.Counts {
+	return q.qb.Sample(shots, Index(qb...)...)
+}
+
+// ExpectPauli returns the expectation value of the Pauli string such as "XZI".
+// The i-th character of s acts on qb[i], or on the i-th qubit if no qubits are given.
+// It returns an error if s is not a valid Pauli string for the qubits.
+func (q *Q) ExpectPauli(s string, qb ...Qubit) (float64, error) {
+	return q.qb.ExpectPauli(s, Index(qb...)...)
+}
+
+// ExpectPauliSum returns the expectation value of the weighted sum of Pauli strings.
+// The i-th Pauli string s[i] has the coefficient coef[i].
+// It returns an error if the numbers of coefficients and Pauli strings differ or a Pauli string is invalid.
+func (q *Q) ExpectPauliSum(coef []float64, s []string, qb ...Qubit) (float64, error) {
+	return q.qb.ExpectPauliSum(coef, s, Index(qb...)...)
+}
+
+// Schmidt returns the Schmidt coefficients and the local basis states
+// across the bipartition of the given qubits and the other qubits.
+func (q *Q) Schmidt(qb ...Qubit) ([]float64, []*qubit.Qubit, []*qubit.Qubit) {
+	return q.qb.Schmidt(Index(qb...)...)
+}
+
+// EntanglementEntropy returns the entanglement entropy
+// across the bipartition of the given qubits and the other qubits.
+func (q *Q) EntanglementEntropy(qb ...Qubit) float64 {
+	return q.qb.EntanglementEntropy(Index(qb...)...)
+}
+
 // Clone returns a copy of q.
 func (q *Q) Clone() *Q {
 	return &Q{
-		qb: q.qb.Clone(),
+		qb:   q.qb.Clone(),
+		bits: maps.Clone(q.bits),
 	}
 }
 
@@ -197,6 +197,23 @@ func ExampleQ_Apply() {
 	// [11] ( 0.7071 0.0000i): 0.5000
 }
 
+func ExampleQ_ApplyAt() {
+	qsim := q.New()
+
+	qb := qsim.Zeros(3)
+
+	qsim.H(qb[2])
+	qsim.ApplyAt(gate.CNOT(2, 0, 1), qb[2], qb[0])
+
+	for _, s := range qsim.State() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [000] ( 0.7071 0.0000i): 0.5000
+	// [101] ( 0.7071 0.0000i): 0.5000
+}
+
 func ExampleQ_U() {
 	qsim := q.New()
 
@@ -1096,3 +1113,60 @@ func Example_entropy() {
 	// 1.0000
 	// +Inf
 }
+
+func ExampleQ_Sample() {
+	qsim := q.New()
+	qsim.SetRand(rand.Const())
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	q2 := qsim.Zero()
+	qsim.H(q0, q1).CNOT(q1, q2)
+
+	counts := qsim.Sample(1000, q1, q2)
+	fmt.Println(len(counts), counts["00"]+counts["11"])
+	fmt.Println(len(qsim.State()))
+
+	// Output:
+	// 2 1000
+	// 4
+}
+
+func ExampleQ_ExpectPauli() {
+	qsim := q.New()
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	q2 := qsim.Zero()
+	qsim.H(q0).CNOT(q0, q2).X(q1)
+
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("ZZ", q0, q2)))
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauli("IZ")))
+	fmt.Printf("%.4f\n", number.Must(qsim.ExpectPauliSum([]float64{1, 2}, []string{"XX", "ZI"}, q0, q2)))
+
+	// Output:
+	// 1.0000
+	// -1.0000
+	// 1.0000
+}
+
+func ExampleQ_Schmidt() {
+	qsim := q.New()
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	q2 := qsim.Zero()
+	qsim.H(q0).CNOT(q0, q2).H(q1)
+
+	s, a, b := qsim.Schmidt(q0)
+	fmt.Printf("%.4f\n", s)
+	fmt.Println(a[0].NumQubits(), b[0].NumQubits())
+	fmt.Printf("%.4f\n", qsim.EntanglementEntropy(q0))
+	fmt.Printf("%.4f\n", qsim.EntanglementEntropy(q1))
+
+	// Output:
+	// [0.7071 0.7071]
+	// 1 2
+	// 1.0000
+	// 0.0000
+}
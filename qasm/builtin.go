@@ -0,0 +1,154 @@
+package qasm
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/itsubaki/q"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+// builtin is a gate of OpenQASM 2.0 and qelib1.inc.
+type builtin struct {
+	core   bool // available without including qelib1.inc
+	params int
+	qubits int
+	ops    func(p []float64, qb []q.Qubit) []q.Op
+}
+
+var builtins = map[string]builtin{
+	"U":     {true, 3, 1, u3},
+	"CX":    {true, 0, 2, controlled(q.OpControlledX)},
+	"u3":    {false, 3, 1, u3},
+	"u":     {false, 3, 1, u3},
+	"u2":    {false, 2, 1, u2},
+	"u1":    {false, 1, 1, single(q.OpR)},
+	"p":     {false, 1, 1, single(q.OpR)},
+	"u0":    {false, 1, 1, id},
+	"id":    {false, 0, 1, single(q.OpI)},
+	"x":     {false, 0, 1, single(q.OpX)},
+	"y":     {false, 0, 1, single(q.OpY)},
+	"z":     {false, 0, 1, single(q.OpZ)},
+	"h":     {false, 0, 1, single(q.OpH)},
+	"s":     {false, 0, 1, single(q.OpS)},
+	"t":     {false, 0, 1, single(q.OpT)},
+	"sdg":   {false, 0, 1, phase(-math.Pi / 2)},
+	"tdg":   {false, 0, 1, phase(-math.Pi / 4)},
+	"sx":    {false, 0, 1, matrixOf(sx())},
+	"sxdg":  {false, 0, 1, matrixOf(sx().Dagger())},
+	"rx":    {false, 1, 1, single(q.OpRX)},
+	"ry":    {false, 1, 1, single(q.OpRY)},
+	"rz":    {false, 1, 1, single(q.OpRZ)},
+	"cx":    {false, 0, 2, controlled(q.OpControlledX)},
+	"cy":    {false, 0, 2, controlledMatrix(func([]float64) *matrix.Matrix { return gate.Y() })},
+	"cz":    {false, 0, 2, controlled(q.OpControlledZ)},
+	"ch":    {false, 0, 2, controlled(q.OpControlledH)},
+	"csx":   {false, 0, 2, controlledMatrix(func([]float64) *matrix.Matrix { return sx() })},
+	"ccx":   {false, 0, 3, controlled(q.OpControlledX)},
+	"crx":   {false, 1, 2, controlledMatrix(func(p []float64) *matrix.Matrix { return gate.RX(p[0]) })},
+	"cry":   {false, 1, 2, controlledMatrix(func(p []float64) *matrix.Matrix { return gate.RY(p[0]) })},
+	"crz":   {false, 1, 2, controlledMatrix(func(p []float64) *matrix.Matrix { return gate.RZ(p[0]) })},
+	"cu1":   {false, 1, 2, controlled(q.OpControlledR)},
+	"cp":    {false, 1, 2, controlled(q.OpControlledR)},
+	"cu3":   {false, 3, 2, controlled(q.OpControlledU)},
+	"cu":    {false, 4, 2, controlledMatrix(cu)},
+	"swap":  {false, 0, 2, swap},
+	"cswap": {false, 0, 3, cswap},
+	"rzz":   {false, 1, 2, rzz},
+}
+
+// controlledTypes maps the type of an operation to the type with additional controls.
+var controlledTypes = map[q.OpType]q.OpType{
+	q.OpX:           q.OpControlledX,
+	q.OpZ:           q.OpControlledZ,
+	q.OpH:           q.OpControlledH,
+	q.OpR:           q.OpControlledR,
+	q.OpU:           q.OpControlledU,
+	q.OpG:           q.OpControlled,
+	q.OpControlledX: q.OpControlledX,
+	q.OpControlledZ: q.OpControlledZ,
+	q.OpControlledH: q.OpControlledH,
+	q.OpControlledR: q.OpControlledR,
+	q.OpControlledU: q.OpControlledU,
+	q.OpControlled:  q.OpControlled,
+}
+
+// sx returns the square root of the X gate.
+func sx() *matrix.Matrix {
+	return matrix.New(
+		[]complex128{(1 + 1i) / 2, (1 - 1i) / 2},
+		[]complex128{(1 - 1i) / 2, (1 + 1i) / 2},
+	)
+}
+
+func u3(p []float64, qb []q.Qubit) []q.Op {
+	return []q.Op{{Type: q.OpU, Target: qb, Params: p}}
+}
+
+func u2(p []float64, qb []q.Qubit) []q.Op {
+	return u3([]float64{math.Pi / 2, p[0], p[1]}, qb)
+}
+
+func id(_ []float64, qb []q.Qubit) []q.Op {
+	return []q.Op{{Type: q.OpI, Target: qb}}
+}
+
+func single(t q.OpType) func(p []float64, qb []q.Qubit) []q.Op {
+	return func(p []float64, qb []q.Qubit) []q.Op {
+		return []q.Op{{Type: t, Target: qb, Params: p}}
+	}
+}
+
+func phase(theta float64) func(p []float64, qb []q.Qubit) []q.Op {
+	return func(_ []float64, qb []q.Qubit) []q.Op {
+		return []q.Op{{Type: q.OpR, Target: qb, Params: []float64{theta}}}
+	}
+}
+
+func matrixOf(g *matrix.Matrix) func(p []float64, qb []q.Qubit) []q.Op {
+	return func(_ []float64, qb []q.Qubit) []q.Op {
+		return []q.Op{{Type: q.OpG, Target: qb, Matrix: g}}
+	}
+}
+
+// controlled returns the operation whose last qubit is the target and the others are controls.
+func controlled(t q.OpType) func(p []float64, qb []q.Qubit) []q.Op {
+	return func(p []float64, qb []q.Qubit) []q.Op {
+		n := len(qb) - 1
+		return []q.Op{{Type: t, Control: qb[:n:n], Target: qb[n:], Params: p}}
+	}
+}
+
+func controlledMatrix(g func(p []float64) *matrix.Matrix) func(p []float64, qb []q.Qubit) []q.Op {
+	return func(p []float64, qb []q.Qubit) []q.Op {
+		n := len(qb) - 1
+		return []q.Op{{Type: q.OpControlled, Control: qb[:n:n], Target: qb[n:], Matrix: g(p)}}
+	}
+}
+
+func cu(p []float64) *matrix.Matrix {
+	return gate.U(p[0], p[1], p[2]).Mul(cmplx.Exp(complex(0, p[3])))
+}
+
+func swap(_ []float64, qb []q.Qubit) []q.Op {
+	return []q.Op{{Type: q.OpSwap, Target: qb}}
+}
+
+func cswap(_ []float64, qb []q.Qubit) []q.Op {
+	a, b, c := qb[0], qb[1], qb[2]
+	return []q.Op{
+		{Type: q.OpControlledX, Control: []q.Qubit{c}, Target: []q.Qubit{b}},
+		{Type: q.OpControlledX, Control: []q.Qubit{a, b}, Target: []q.Qubit{c}},
+		{Type: q.OpControlledX, Control: []q.Qubit{c}, Target: []q.Qubit{b}},
+	}
+}
+
+func rzz(p []float64, qb []q.Qubit) []q.Op {
+	a, b := qb[0], qb[1]
+	return []q.Op{
+		{Type: q.OpControlledX, Control: []q.Qubit{a}, Target: []q.Qubit{b}},
+		{Type: q.OpR, Target: []q.Qubit{b}, Params: p},
+		{Type: q.OpControlledX, Control: []q.Qubit{a}, Target: []q.Qubit{b}},
+	}
+}
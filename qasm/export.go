@@ -0,0 +1,245 @@
+package qasm
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"strings"
+
+	"github.com/itsubaki/q"
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+// Export returns the OpenQASM 3 source of the given circuit.
+// Qubits are declared as the register q and classical bits as the register c.
+func Export(c *q.Circuit) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("OPENQASM 3.0;\n")
+	sb.WriteString("include \"stdgates.inc\";\n")
+
+	if c.NumQubits > 0 {
+		sb.WriteString(fmt.Sprintf("qubit[%d] q;\n", c.NumQubits))
+	}
+
+	if c.NumClbits > 0 {
+		sb.WriteString(fmt.Sprintf("bit[%d] c;\n", c.NumClbits))
+	}
+
+	for i, op := range c.Ops {
+		stmt, err := statement(op)
+		if err != nil {
+			return "", fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		for _, s := range stmt {
+			if op.Cond != nil {
+				s = fmt.Sprintf("if (%s) %s", condition(op.Cond, c.NumClbits), s)
+			}
+
+			sb.WriteString(s + "\n")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// statement returns the OpenQASM 3 statements of op.
+func statement(op q.Op) ([]string, error) {
+	target := args(op.Target...)
+	switch op.Type {
+	case q.OpNew:
+		return prepare(op)
+	case q.OpI:
+		return []string{"id " + target + ";"}, nil
+	case q.OpX, q.OpY, q.OpZ, q.OpH, q.OpS, q.OpT:
+		return []string{strings.ToLower(string(op.Type)) + " " + target + ";"}, nil
+	case q.OpR:
+		return []string{callStmt("p", op.Params) + " " + target + ";"}, nil
+	case q.OpRX, q.OpRY, q.OpRZ:
+		return []string{callStmt(strings.ToLower(string(op.Type)), op.Params) + " " + target + ";"}, nil
+	case q.OpU:
+		return []string{callStmt("U", op.Params) + " " + target + ";"}, nil
+	case q.OpG, q.OpApplyAt:
+		return unitary(op.Matrix, nil, op.Target)
+	case q.OpControlled:
+		return unitary(op.Matrix, op.Control, op.Target)
+	case q.OpControlledX:
+		return ctrlStmt("x", "cx", "ccx", op), nil
+	case q.OpControlledZ:
+		return ctrlStmt("z", "cz", "", op), nil
+	case q.OpControlledH:
+		return ctrlStmt("h", "ch", "", op), nil
+	case q.OpControlledR:
+		return ctrlStmt(callStmt("p", op.Params), callStmt("cp", op.Params), "", op), nil
+	case q.OpControlledU:
+		return ctrlStmt(callStmt("U", op.Params), "", "", op), nil
+	case q.OpSwap:
+		return []string{"swap " + target + ";"}, nil
+	case q.OpMeasure:
+		if len(op.Clbit) != len(op.Target) {
+			return nil, fmt.Errorf("measurement of %d qubits into %d classical bits", len(op.Target), len(op.Clbit))
+		}
+
+		stmt := make([]string, len(op.Target))
+		for i := range op.Target {
+			stmt[i] = fmt.Sprintf("c[%d] = measure %s;", op.Clbit[i], args(op.Target[i]))
+		}
+
+		return stmt, nil
+	case q.OpReset:
+		return []string{"reset " + target + ";"}, nil
+	case q.OpBarrier:
+		if len(op.Target) == 0 {
+			return []string{"barrier q;"}, nil
+		}
+
+		return []string{"barrier " + target + ";"}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported operation %v", op.Type)
+}
+
+// ctrlStmt returns the statement of a controlled gate.
+// single and double are the names of the gates with one and two controls, if any.
+func ctrlStmt(name, single, double string, op q.Op) []string {
+	qb := args(op.Qubits()...)
+	switch {
+	case len(op.Control) == 1 && single != "":
+		return []string{single + " " + qb + ";"}
+	case len(op.Control) == 2 && double != "":
+		return []string{double + " " + qb + ";"}
+	}
+
+	return []string{ctrl(len(op.Control)) + name + " " + qb + ";"}
+}
+
+// unitary returns the statements of a 2x2 unitary gate with the given controls.
+func unitary(u *matrix.Matrix, control, target []q.Qubit) ([]string, error) {
+	if u.Rows != 2 || u.Cols != 2 {
+		return nil, fmt.Errorf("unsupported %dx%d matrix", u.Rows, u.Cols)
+	}
+
+	alpha, theta, phi, lambda := gate.Euler(u)
+	stmt := []string{
+		ctrl(len(control)) + callStmt("U", []float64{theta, phi, lambda}) + " " + args(append(append([]q.Qubit{}, control...), target...)...) + ";",
+	}
+
+	if epsilon.IsZeroF64(alpha, 1e-12) {
+		return stmt, nil
+	}
+
+	if len(control) == 0 {
+		return append(stmt, callStmt("gphase", []float64{alpha})+";"), nil
+	}
+
+	return append(stmt, ctrl(len(control))+callStmt("gphase", []float64{alpha})+" "+args(control...)+";"), nil
+}
+
+// prepare returns the statements that prepare the initial state of a new qubit.
+func prepare(op q.Op) ([]string, error) {
+	if len(op.State) != 2 {
+		return nil, fmt.Errorf("unsupported initial state of %d qubits", len(op.Target))
+	}
+
+	a, b := op.State[0], op.State[1]
+	if epsilon.IsZero(b) {
+		return nil, nil
+	}
+
+	theta := 2 * math.Atan2(cmplx.Abs(b), cmplx.Abs(a))
+	phi := cmplx.Phase(b) - cmplx.Phase(a)
+	if epsilon.IsZero(a) {
+		phi = 0
+	}
+
+	if epsilon.IsCloseF64(theta, math.Pi) && epsilon.IsZeroF64(phi) {
+		return []string{"x " + args(op.Target...) + ";"}, nil
+	}
+
+	return []string{callStmt("U", []float64{theta, phi, 0}) + " " + args(op.Target...) + ";"}, nil
+}
+
+func ctrl(n int) string {
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return "ctrl @ "
+	}
+
+	return fmt.Sprintf("ctrl(%d) @ ", n)
+}
+
+func callStmt(name string, params []float64) string {
+	p := make([]string, len(params))
+	for i := range params {
+		p[i] = angle(params[i])
+	}
+
+	return name + "(" + strings.Join(p, ", ") + ")"
+}
+
+func args(qb ...q.Qubit) string {
+	list := make([]string, len(qb))
+	for i := range qb {
+		list[i] = fmt.Sprintf("q[%d]", qb[i].Index())
+	}
+
+	return strings.Join(list, ", ")
+}
+
+// condition returns the condition expression of c.
+// If c refers to all the n classical bits in order, it compares the register c with the value.
+func condition(c *q.Condition, n int) string {
+	whole := len(c.Clbit) == n
+	for i, b := range c.Clbit {
+		whole = whole && b == i
+	}
+
+	if whole {
+		return fmt.Sprintf("c == %d", c.Value)
+	}
+
+	list := make([]string, len(c.Clbit))
+	for i, b := range c.Clbit {
+		list[i] = fmt.Sprintf("c[%d] == %d", b, (c.Value>>i)&1)
+	}
+
+	return strings.Join(list, " && ")
+}
+
+// angle returns the string representation of v.
+// Rational multiples of pi with a power-of-two denominator are written in terms of pi.
+func angle(v float64) string {
+	if v == 0 {
+		return "0"
+	}
+
+	for d := 1; d <= 1<<16; d *= 2 {
+		n := math.Round(v * float64(d) / math.Pi)
+		if n == 0 || math.Abs(n) > float64(4*d) || !epsilon.IsCloseF64(v, n*math.Pi/float64(d), 1e-12) {
+			continue
+		}
+
+		var s string
+		switch n {
+		case 1:
+			s = "pi"
+		case -1:
+			s = "-pi"
+		default:
+			s = strconv.Itoa(int(n)) + "*pi"
+		}
+
+		if d == 1 {
+			return s
+		}
+
+		return s + "/" + strconv.Itoa(d)
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,356 @@
+package qasm_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/itsubaki/q"
+	F "github.com/itsubaki/q/function"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/qasm"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleExport() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	qsim.H(q0)
+	qsim.CNOT(q0, q1)
+	qsim.Measure(q0, q1)
+
+	src, err := qasm.Export(c)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Print(src)
+
+	// Output:
+	// OPENQASM 3.0;
+	// include "stdgates.inc";
+	// qubit[2] q;
+	// bit[2] c;
+	// h q[0];
+	// cx q[0], q[1];
+	// c[0] = measure q[0];
+	// c[1] = measure q[1];
+}
+
+func ExampleExport_qft() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	qb := qsim.Zeros(3)
+	F.QFT(qsim, qb...)
+	F.Swap(qsim, qb...)
+	F.InvQFT(qsim, qb...)
+
+	src, err := qasm.Export(c)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Print(src)
+
+	// Output:
+	// OPENQASM 3.0;
+	// include "stdgates.inc";
+	// qubit[3] q;
+	// h q[0];
+	// cp(pi/2) q[0], q[1];
+	// cp(pi/4) q[0], q[2];
+	// h q[1];
+	// cp(pi/2) q[1], q[2];
+	// h q[2];
+	// swap q[0], q[2];
+	// h q[2];
+	// cp(-pi/2) q[2], q[1];
+	// h q[1];
+	// cp(-pi/4) q[2], q[0];
+	// cp(-pi/2) q[1], q[0];
+	// h q[0];
+}
+
+func ExampleExport_controlled() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	qb := qsim.Zeros(4)
+	qsim.One()
+	qsim.New(1, 1)
+	qsim.CCNOT(qb[0], qb[1], qb[2])
+	qsim.CCCNOT(qb[0], qb[1], qb[2], qb[3])
+	qsim.ControlledZ(qb[:3], qb[3:])
+	qsim.ControlledR(q.Theta(3), qb[:2], qb[2:3])
+	qsim.ControlledH(qb[:1], qb[1:2])
+	qsim.CU(1.0, 2.0, 3.0, qb[0], qb[1])
+	qsim.C(gate.S(), qb[0], qb[1])
+	qsim.Controlled(gate.X(), qb[:2], qb[2:3])
+	qsim.G(gate.Y(), qb[0])
+	qsim.U(0, 0, q.Theta(1), qb[0])
+	qsim.RX(0.1, qb[0]).RY(-0.2, qb[0]).RZ(0.3, qb[0]).R(3*q.Theta(4), qb[0])
+	qsim.I(qb[0]).X(qb[0]).Y(qb[0]).Z(qb[0]).S(qb[0]).T(qb[0])
+	qsim.Reset(qb[0])
+
+	src, err := qasm.Export(c)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Print(src)
+
+	// Output:
+	// OPENQASM 3.0;
+	// include "stdgates.inc";
+	// qubit[6] q;
+	// x q[4];
+	// U(pi/2, 0, 0) q[5];
+	// ccx q[0], q[1], q[2];
+	// ctrl(3) @ x q[0], q[1], q[2], q[3];
+	// ctrl(3) @ z q[0], q[1], q[2], q[3];
+	// ctrl(2) @ p(pi/4) q[0], q[1], q[2];
+	// ch q[0], q[1];
+	// ctrl @ U(1, 2, 3) q[0], q[1];
+	// ctrl @ U(0, 0, pi/2) q[0], q[1];
+	// ctrl(2) @ U(pi, 0, pi) q[0], q[1], q[2];
+	// U(pi, 0, 0) q[0];
+	// gphase(pi/2);
+	// U(0, 0, pi) q[0];
+	// rx(0.1) q[0];
+	// ry(-0.2) q[0];
+	// rz(0.3) q[0];
+	// p(3*pi/8) q[0];
+	// id q[0];
+	// x q[0];
+	// y q[0];
+	// z q[0];
+	// s q[0];
+	// t q[0];
+	// reset q[0];
+}
+
+func ExampleExport_cond() {
+	c := q.NewCircuit().Add(
+		q.Op{Type: q.OpH, Target: []q.Qubit{0}},
+		q.Op{Type: q.OpBarrier},
+		q.Op{Type: q.OpMeasure, Target: []q.Qubit{0, 1}, Clbit: []int{0, 1}},
+		q.Op{Type: q.OpX, Target: []q.Qubit{2}, Cond: &q.Condition{Clbit: []int{1}, Value: 1}},
+		q.Op{Type: q.OpZ, Target: []q.Qubit{2}, Cond: &q.Condition{Clbit: []int{0, 1}, Value: 2}},
+		q.Op{Type: q.OpH, Target: []q.Qubit{2}, Cond: &q.Condition{Clbit: []int{1, 0}, Value: 1}},
+		q.Op{Type: q.OpBarrier, Target: []q.Qubit{0, 1}},
+	)
+
+	src, err := qasm.Export(c)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Print(src)
+
+	// Output:
+	// OPENQASM 3.0;
+	// include "stdgates.inc";
+	// qubit[3] q;
+	// bit[2] c;
+	// h q[0];
+	// barrier q;
+	// c[0] = measure q[0];
+	// c[1] = measure q[1];
+	// if (c[1] == 1) x q[2];
+	// if (c == 2) z q[2];
+	// if (c[1] == 1 && c[0] == 0) h q[2];
+	// barrier q[0], q[1];
+}
+
+func ExampleExport_ifX() {
+	c := q.NewCircuit()
+
+	qsim := q.New()
+	qsim.Record(c)
+
+	q0 := qsim.Zero()
+	qsim.H(q0)
+	qsim.Measure(q0)
+	qsim.IfX([]q.Qubit{q0}, 1, q0)
+
+	src, err := qasm.Export(c)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Print(src)
+
+	// Output:
+	// OPENQASM 3.0;
+	// include "stdgates.inc";
+	// qubit[1] q;
+	// bit[1] c;
+	// h q[0];
+	// c[0] = measure q[0];
+	// if (c == 1) x q[0];
+}
+
+func ExampleExport_gphase() {
+	c := q.NewCircuit().Add(
+		q.Op{Type: q.OpG, Target: []q.Qubit{0}, Matrix: gate.RZ(q.Theta(1))},
+		q.Op{Type: q.OpControlled, Control: []q.Qubit{0}, Target: []q.Qubit{1}, Matrix: gate.RZ(q.Theta(1))},
+	)
+
+	src, err := qasm.Export(c)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Print(src)
+
+	// Output:
+	// OPENQASM 3.0;
+	// include "stdgates.inc";
+	// qubit[2] q;
+	// U(0, 0, pi) q[0];
+	// gphase(-pi/2);
+	// ctrl @ U(0, 0, pi) q[0], q[1];
+	// ctrl @ gphase(-pi/2) q[0];
+}
+
+func TestExport_error(t *testing.T) {
+	cases := []struct {
+		in   *q.Circuit
+		want string
+	}{
+		{
+			q.NewCircuit().Add(q.Op{Type: q.OpApply, Matrix: gate.CNOT(2, 0, 1)}),
+			"operation 0: unsupported operation Apply",
+		},
+		{
+			q.NewCircuit().Add(q.Op{Type: q.OpG, Target: []q.Qubit{0}, Matrix: matrix.Identity(4)}),
+			"operation 0: unsupported 4x4 matrix",
+		},
+		{
+			q.NewCircuit().Add(q.Op{Type: q.OpNew, Target: []q.Qubit{0, 1}, State: []complex128{1, 0, 0, 0}}),
+			"operation 0: unsupported initial state of 2 qubits",
+		},
+		{
+			q.NewCircuit().Add(q.Op{Type: q.OpMeasure, Target: []q.Qubit{0, 1}, Clbit: []int{0}}),
+			"operation 0: measurement of 2 qubits into 1 classical bits",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := qasm.Export(c.in)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+	}
+}
+
+func TestExport_roundTrip(t *testing.T) {
+	cases := []struct {
+		f func(qsim *q.Q)
+	}{
+		{
+			// quantum teleportation
+			func(qsim *q.Q) {
+				psi := qsim.New(1, 2)
+				q0 := qsim.Zero()
+				q1 := qsim.Zero()
+
+				qsim.H(q0)
+				qsim.CNOT(q0, q1)
+				qsim.CNOT(psi, q0)
+				qsim.H(psi)
+				qsim.Measure(psi, q0)
+				qsim.IfX([]q.Qubit{q0}, 1, q1)
+				qsim.IfZ([]q.Qubit{psi}, 1, q1)
+			},
+		},
+		{
+			func(qsim *q.Q) {
+				qb := qsim.Zeros(3)
+				qsim.H(qb[0], qb[1])
+				qsim.Measure(qb[0], qb[1])
+				qsim.If(qb[:2], 2, gate.H(), qb[2])
+				qsim.If(qb[:2], 3, gate.RY(0.3), qb[2])
+				qsim.IfX([]q.Qubit{qb[1], qb[0]}, 1, qb[2])
+			},
+		},
+		{
+			func(qsim *q.Q) {
+				qb := qsim.Zeros(4)
+				qsim.H(qb...)
+				qsim.CCCNOT(qb[0], qb[1], qb[2], qb[3])
+				qsim.ControlledZ(qb[:2], qb[2:3])
+				qsim.ControlledR(0.3, qb[1:3], qb[3:])
+				qsim.ControlledH(qb[:1], qb[1:2])
+				qsim.CU(0.1, 0.2, 0.3, qb[2], qb[0])
+				qsim.C(gate.RZ(0.4), qb[3], qb[1])
+				qsim.Controlled(gate.RX(0.5), qb[:3], qb[3:])
+			},
+		},
+		{
+			func(qsim *q.Q) {
+				q0 := qsim.New(1, 2)
+				q1 := qsim.One()
+				qsim.Swap(q0, q1)
+				qsim.Measure(q0)
+				qsim.Reset(q0)
+				qsim.RX(0.1, q0).RY(0.2, q1).RZ(0.3, q0)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		circuit := q.NewCircuit()
+
+		qsim := q.New()
+		qsim.Record(circuit)
+		c.f(qsim)
+
+		src, err := qasm.Export(circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := qasm.Parse(src)
+		if err != nil {
+			t.Fatalf("src=%v, err=%v", src, err)
+		}
+
+		for seed := range 10 {
+			want := q.New()
+			want.SetRand(rand.Const(uint64(seed)))
+			wbits := want.Run(circuit)
+
+			got := q.New()
+			got.SetRand(rand.Const(uint64(seed)))
+			gbits := got.Run(parsed)
+
+			if !slices.Equal(gbits, wbits) {
+				t.Errorf("src=%v, got=%v, want=%v", src, gbits, wbits)
+			}
+
+			if !qubit.EqualUpToGlobalPhase(got.State(), want.State()) {
+				t.Errorf("src=%v, got=%v, want=%v", src, got.State(), want.State())
+			}
+		}
+	}
+}
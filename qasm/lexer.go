@@ -0,0 +1,124 @@
+package qasm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type kind int
+
+const (
+	eofKind kind = iota
+	identKind
+	numberKind
+	stringKind
+	symbolKind
+)
+
+type token struct {
+	kind kind
+	text string
+	line int
+	col  int
+}
+
+// Error is an error with the position in the source.
+type Error struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error returns the string representation of e.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+func errorf(t token, format string, a ...any) error {
+	return &Error{
+		Line:   t.line,
+		Column: t.col,
+		Msg:    fmt.Sprintf(format, a...),
+	}
+}
+
+// lex splits src into tokens.
+func lex(src string) ([]token, error) {
+	r := []rune(src)
+	line, col := 1, 1
+
+	var tokens []token
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case c == '\n':
+			line, col = line+1, 1
+			i++
+		case unicode.IsSpace(c):
+			col++
+			i++
+		case c == '/' && i+1 < len(r) && r[i+1] == '/':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_') {
+				j++
+			}
+
+			tokens = append(tokens, token{identKind, string(r[i:j]), line, col})
+			col += j - i
+			i = j
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(r) && unicode.IsDigit(r[i+1])):
+			j := i
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.') {
+				j++
+			}
+
+			if j < len(r) && (r[j] == 'e' || r[j] == 'E') {
+				k := j + 1
+				if k < len(r) && (r[k] == '+' || r[k] == '-') {
+					k++
+				}
+
+				if k < len(r) && unicode.IsDigit(r[k]) {
+					j = k
+					for j < len(r) && unicode.IsDigit(r[j]) {
+						j++
+					}
+				}
+			}
+
+			tokens = append(tokens, token{numberKind, string(r[i:j]), line, col})
+			col += j - i
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(r) && r[j] != '"' && r[j] != '\n' {
+				j++
+			}
+
+			if j == len(r) || r[j] != '"' {
+				return nil, &Error{Line: line, Column: col, Msg: "unterminated string"}
+			}
+
+			tokens = append(tokens, token{stringKind, string(r[i+1 : j]), line, col})
+			col += j + 1 - i
+			i = j + 1
+		case c == '-' && i+1 < len(r) && r[i+1] == '>', c == '=' && i+1 < len(r) && r[i+1] == '=', c == '&' && i+1 < len(r) && r[i+1] == '&':
+			tokens = append(tokens, token{symbolKind, string(r[i : i+2]), line, col})
+			col += 2
+			i += 2
+		case strings.ContainsRune(";,()[]{}+-*/^=@", c):
+			tokens = append(tokens, token{symbolKind, string(c), line, col})
+			col++
+			i++
+		default:
+			return nil, &Error{Line: line, Column: col, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return append(tokens, token{eofKind, "", line, col}), nil
+}
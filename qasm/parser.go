@@ -0,0 +1,1058 @@
+// Package qasm converts between OpenQASM source and q.Circuit.
+package qasm
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/itsubaki/q"
+)
+
+// Parse returns the circuit of the given OpenQASM 2.0 source.
+// It also accepts the subset of OpenQASM 3 written by Export.
+func Parse(src string) (*q.Circuit, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		tokens:  tokens,
+		qreg:    make(map[string]register),
+		creg:    make(map[string]register),
+		gates:   make(map[string]*gateDef),
+		circuit: q.NewCircuit(),
+	}
+
+	for p.peek().kind != eofKind {
+		if err := p.statement(); err != nil {
+			return nil, err
+		}
+	}
+
+	p.circuit.NumQubits = max(p.circuit.NumQubits, p.numQubits)
+	p.circuit.NumClbits = max(p.circuit.NumClbits, p.numClbits)
+	return p.circuit, nil
+}
+
+// Load returns a quantum computing simulator that ran the given OpenQASM source,
+// and the classical bits.
+func Load(src string) (*q.Q, []int, error) {
+	c, err := Parse(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	qsim := q.New()
+	bits := qsim.Run(c)
+	return qsim, bits, nil
+}
+
+// register is a quantum or classical register.
+type register struct {
+	offset int
+	size   int
+}
+
+// argument is a register or an element of a register.
+type argument struct {
+	tok   token
+	index int // -1 for the whole register
+}
+
+// expr is an expression evaluated with the gate parameters.
+type expr func(env map[string]float64) float64
+
+// call is a gate call in a gate definition.
+type call struct {
+	tok    token
+	params []expr
+	args   []token
+}
+
+// gateDef is a gate definition.
+type gateDef struct {
+	params []string
+	args   []string
+	body   []call
+}
+
+type parser struct {
+	tokens    []token
+	pos       int
+	qelib     bool
+	qreg      map[string]register
+	creg      map[string]register
+	numQubits int
+	numClbits int
+	gates     map[string]*gateDef
+	circuit   *q.Circuit
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != eofKind {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) accept(text string) bool {
+	if t := p.peek(); t.kind == symbolKind && t.text == text {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(text string) (token, error) {
+	t := p.next()
+	if t.kind != symbolKind || t.text != text {
+		return t, errorf(t, "expected %q, found %q", text, t.text)
+	}
+
+	return t, nil
+}
+
+func (p *parser) ident() (token, error) {
+	t := p.next()
+	if t.kind != identKind {
+		return t, errorf(t, "expected identifier, found %q", t.text)
+	}
+
+	return t, nil
+}
+
+func (p *parser) integer() (int, error) {
+	t := p.next()
+	v, err := strconv.Atoi(t.text)
+	if t.kind != numberKind || err != nil {
+		return 0, errorf(t, "expected integer, found %q", t.text)
+	}
+
+	return v, nil
+}
+
+func (p *parser) statement() error {
+	t := p.peek()
+	if t.kind != identKind {
+		return errorf(t, "unexpected %q", t.text)
+	}
+
+	switch t.text {
+	case "OPENQASM":
+		p.next()
+		v := p.next()
+		if v.text != "2.0" && v.text != "2" && v.text != "3.0" && v.text != "3" {
+			return errorf(v, "unsupported version %q", v.text)
+		}
+
+		_, err := p.expect(";")
+		return err
+	case "include":
+		p.next()
+		f := p.next()
+		if f.kind != stringKind || (f.text != "qelib1.inc" && f.text != "stdgates.inc") {
+			return errorf(f, "unsupported include %q", f.text)
+		}
+
+		p.qelib = true
+		_, err := p.expect(";")
+		return err
+	case "qreg", "creg":
+		return p.register()
+	case "qubit", "bit":
+		return p.declare()
+	case "gate":
+		return p.gate()
+	case "opaque":
+		return errorf(t, "opaque gates are not supported")
+	case "if":
+		p.next()
+		cond, err := p.condition()
+		if err != nil {
+			return err
+		}
+
+		return p.operation(cond)
+	}
+
+	return p.operation(nil)
+}
+
+func (p *parser) register() error {
+	t := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := p.qreg[name.text]; ok {
+		return errorf(name, "register %q is already declared", name.text)
+	}
+
+	if _, ok := p.creg[name.text]; ok {
+		return errorf(name, "register %q is already declared", name.text)
+	}
+
+	if _, err := p.expect("["); err != nil {
+		return err
+	}
+
+	size, err := p.integer()
+	if err != nil {
+		return err
+	}
+
+	if size < 1 {
+		return errorf(name, "register %q must have a positive size", name.text)
+	}
+
+	if _, err := p.expect("]"); err != nil {
+		return err
+	}
+
+	if _, err := p.expect(";"); err != nil {
+		return err
+	}
+
+	p.add(t.text == "qreg", name.text, size)
+	return nil
+}
+
+// declare parses the OpenQASM 3 declaration of a qubit or bit register such as "qubit[2] q;".
+func (p *parser) declare() error {
+	t := p.next()
+
+	size := 1
+	if p.accept("[") {
+		s := p.peek()
+		v, err := p.integer()
+		if err != nil {
+			return err
+		}
+
+		if v < 1 {
+			return errorf(s, "register size must be positive")
+		}
+
+		if _, err := p.expect("]"); err != nil {
+			return err
+		}
+
+		size = v
+	}
+
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := p.qreg[name.text]; ok {
+		return errorf(name, "register %q is already declared", name.text)
+	}
+
+	if _, ok := p.creg[name.text]; ok {
+		return errorf(name, "register %q is already declared", name.text)
+	}
+
+	if _, err := p.expect(";"); err != nil {
+		return err
+	}
+
+	p.add(t.text == "qubit", name.text, size)
+	return nil
+}
+
+// add adds the quantum or classical register of the given size.
+func (p *parser) add(quantum bool, name string, size int) {
+	if quantum {
+		p.qreg[name] = register{p.numQubits, size}
+		p.numQubits += size
+		return
+	}
+
+	p.creg[name] = register{p.numClbits, size}
+	p.numClbits += size
+}
+
+func (p *parser) gate() error {
+	p.next()
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := p.gates[name.text]; ok {
+		return errorf(name, "gate %q is already defined", name.text)
+	}
+
+	def := &gateDef{}
+	if p.accept("(") && !p.accept(")") {
+		if def.params, err = p.identList(")"); err != nil {
+			return err
+		}
+	}
+
+	if def.args, err = p.identList("{"); err != nil {
+		return err
+	}
+
+	env := make(map[string]bool)
+	for _, v := range def.params {
+		env[v] = true
+	}
+
+	args := make(map[string]bool)
+	for _, v := range def.args {
+		args[v] = true
+	}
+
+	for !p.accept("}") {
+		c := call{}
+		if c.tok, err = p.ident(); err != nil {
+			return err
+		}
+
+		if p.accept("(") && !p.accept(")") {
+			if c.params, err = p.exprList(env); err != nil {
+				return err
+			}
+		}
+
+		for {
+			a, err := p.ident()
+			if err != nil {
+				return err
+			}
+
+			if !args[a.text] {
+				return errorf(a, "undefined argument %q", a.text)
+			}
+
+			c.args = append(c.args, a)
+			if !p.accept(",") {
+				break
+			}
+		}
+
+		if _, err := p.expect(";"); err != nil {
+			return err
+		}
+
+		// the gates called in the body must be defined before, which also rules out recursion.
+		if c.tok.text != "barrier" {
+			params, qubits, err := p.signature(c.tok)
+			if err != nil {
+				return err
+			}
+
+			if len(c.params) != params || len(c.args) != qubits {
+				return errorf(c.tok, "gate %q takes %d parameters and %d qubits", c.tok.text, params, qubits)
+			}
+		}
+
+		def.body = append(def.body, c)
+	}
+
+	p.gates[name.text] = def
+	return nil
+}
+
+// identList parses a comma-separated list of identifiers terminated by end.
+func (p *parser) identList(end string) ([]string, error) {
+	var list []string
+	for {
+		t, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, t.text)
+		if p.accept(",") {
+			continue
+		}
+
+		if _, err := p.expect(end); err != nil {
+			return nil, err
+		}
+
+		return list, nil
+	}
+}
+
+// condition parses a condition such as "(c==1)" or "(c[0] == 1 && c[1] == 0)".
+func (p *parser) condition() (*q.Condition, error) {
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	cond := &q.Condition{}
+	for {
+		if t := p.peek(); p.creg[t.text].size == 0 {
+			return nil, errorf(t, "undefined classical register %q", t.text)
+		}
+
+		arg, err := p.argument(p.creg)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect("=="); err != nil {
+			return nil, err
+		}
+
+		t := p.peek()
+		v, err := p.integer()
+		if err != nil {
+			return nil, err
+		}
+
+		clbit := p.resolve(arg, p.creg)
+		if v < 0 || v >= 1<<len(clbit) {
+			return nil, errorf(t, "value %d out of range for %q", v, arg.tok.text)
+		}
+
+		cond.Value |= v << len(cond.Clbit)
+		cond.Clbit = append(cond.Clbit, clbit...)
+		if !p.accept("&&") {
+			break
+		}
+	}
+
+	if _, err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return cond, nil
+}
+
+func (p *parser) operation(cond *q.Condition) error {
+	if _, ok := p.creg[p.peek().text]; ok && p.peek().kind == identKind {
+		return p.assign(cond)
+	}
+
+	ctrl, err := p.modifier()
+	if err != nil {
+		return err
+	}
+
+	t, err := p.ident()
+	if err != nil {
+		return err
+	}
+
+	if ctrl > 0 && (t.text == "measure" || t.text == "reset" || t.text == "barrier") {
+		return errorf(t, "%s cannot be controlled", t.text)
+	}
+
+	switch t.text {
+	case "measure":
+		return p.measure(cond)
+	case "reset":
+		return p.reset(cond)
+	case "barrier":
+		if cond != nil {
+			return errorf(t, "barrier cannot be conditioned")
+		}
+
+		return p.barrier()
+	}
+
+	var params []float64
+	if p.accept("(") && !p.accept(")") {
+		list, err := p.exprList(nil)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range list {
+			params = append(params, e(nil))
+		}
+	}
+
+	var args []argument
+	if t.text != "gphase" || ctrl > 0 {
+		if args, err = p.argList(p.qreg); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.expect(";"); err != nil {
+		return err
+	}
+
+	qubits, err := p.broadcast(args, p.qreg)
+	if err != nil {
+		return err
+	}
+
+	for _, qb := range qubits {
+		if ctrl > 0 || t.text == "gphase" {
+			if err := p.controlled(t, ctrl, params, qb, cond); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := p.apply(t, params, qb, cond); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// modifier parses the OpenQASM 3 control modifiers such as "ctrl @" and "ctrl(2) @",
+// and returns the number of the control qubits.
+func (p *parser) modifier() (int, error) {
+	var n int
+	for p.peek().kind == identKind && p.peek().text == "ctrl" {
+		p.next()
+
+		k := 1
+		if p.accept("(") {
+			t := p.peek()
+			v, err := p.integer()
+			if err != nil {
+				return 0, err
+			}
+
+			if v < 1 {
+				return 0, errorf(t, "number of controls must be positive")
+			}
+
+			if _, err := p.expect(")"); err != nil {
+				return 0, err
+			}
+
+			k = v
+		}
+
+		if _, err := p.expect("@"); err != nil {
+			return 0, err
+		}
+
+		n += k
+	}
+
+	return n, nil
+}
+
+// controlled appends the operations of the gate t with n controls to the circuit.
+// The first n qubits of qb are the controls.
+func (p *parser) controlled(t token, n int, params []float64, qb []q.Qubit, cond *q.Condition) error {
+	if t.text == "gphase" {
+		if len(params) != 1 || len(qb) != n {
+			return errorf(t, "gate %q takes 1 parameters and %d qubits", t.text, n)
+		}
+
+		// the global phase is ignored, and the controlled global phase is the phase gate on the last control.
+		if n == 0 {
+			return nil
+		}
+
+		p.circuit.Add(q.Op{Type: q.OpControlledR, Control: qb[: n-1 : n-1], Target: qb[n-1:], Params: params, Cond: cond})
+		if n == 1 {
+			p.circuit.Ops[len(p.circuit.Ops)-1].Type = q.OpR
+		}
+
+		return nil
+	}
+
+	b, ok := builtins[t.text]
+	if _, def := p.gates[t.text]; def || !ok || (!b.core && !p.qelib) {
+		return errorf(t, "unsupported controlled gate %q", t.text)
+	}
+
+	if len(params) != b.params || len(qb) != n+b.qubits {
+		return errorf(t, "gate %q takes %d parameters and %d qubits", t.text, b.params, n+b.qubits)
+	}
+
+	for _, op := range b.ops(params, qb[n:]) {
+		typ, ok := controlledTypes[op.Type]
+		if !ok {
+			return errorf(t, "unsupported controlled gate %q", t.text)
+		}
+
+		op.Type = typ
+		op.Control = append(append([]q.Qubit{}, qb[:n]...), op.Control...)
+		op.Cond = cond
+		p.circuit.Add(op)
+	}
+
+	return nil
+}
+
+// assign parses the OpenQASM 3 measurement such as "c[0] = measure q[0];".
+func (p *parser) assign(cond *q.Condition) error {
+	dst, err := p.argument(p.creg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.expect("="); err != nil {
+		return err
+	}
+
+	if t, err := p.ident(); err != nil || t.text != "measure" {
+		return errorf(t, "expected \"measure\", found %q", t.text)
+	}
+
+	src, err := p.argument(p.qreg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.expect(";"); err != nil {
+		return err
+	}
+
+	return p.measured(src, dst, cond)
+}
+
+func (p *parser) measure(cond *q.Condition) error {
+	src, err := p.argument(p.qreg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.expect("->"); err != nil {
+		return err
+	}
+
+	dst, err := p.argument(p.creg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.expect(";"); err != nil {
+		return err
+	}
+
+	return p.measured(src, dst, cond)
+}
+
+// measured appends the measurements of src into dst to the circuit.
+func (p *parser) measured(src, dst argument, cond *q.Condition) error {
+	qb := p.resolve(src, p.qreg)
+	cb := p.resolve(dst, p.creg)
+	if len(qb) != len(cb) {
+		return errorf(src.tok, "size mismatch between %q and %q", src.tok.text, dst.tok.text)
+	}
+
+	for i := range qb {
+		p.circuit.Add(q.Op{
+			Type:   q.OpMeasure,
+			Target: []q.Qubit{q.Qubit(qb[i])},
+			Clbit:  []int{cb[i]},
+			Cond:   cond,
+		})
+	}
+
+	return nil
+}
+
+func (p *parser) reset(cond *q.Condition) error {
+	arg, err := p.argument(p.qreg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.expect(";"); err != nil {
+		return err
+	}
+
+	for _, i := range p.resolve(arg, p.qreg) {
+		p.circuit.Add(q.Op{
+			Type:   q.OpReset,
+			Target: []q.Qubit{q.Qubit(i)},
+			Cond:   cond,
+		})
+	}
+
+	return nil
+}
+
+func (p *parser) barrier() error {
+	args, err := p.argList(p.qreg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.expect(";"); err != nil {
+		return err
+	}
+
+	var target []q.Qubit
+	for _, a := range args {
+		for _, i := range p.resolve(a, p.qreg) {
+			target = append(target, q.Qubit(i))
+		}
+	}
+
+	p.circuit.Add(q.Op{
+		Type:   q.OpBarrier,
+		Target: target,
+	})
+
+	return nil
+}
+
+// argument parses a register or an element of a register.
+func (p *parser) argument(regs map[string]register) (argument, error) {
+	t, err := p.ident()
+	if err != nil {
+		return argument{}, err
+	}
+
+	reg, ok := regs[t.text]
+	if !ok {
+		return argument{}, errorf(t, "undefined register %q", t.text)
+	}
+
+	if !p.accept("[") {
+		return argument{tok: t, index: -1}, nil
+	}
+
+	idx := p.peek()
+	i, err := p.integer()
+	if err != nil {
+		return argument{}, err
+	}
+
+	if i < 0 || i >= reg.size {
+		return argument{}, errorf(idx, "index %d out of range for register %q", i, t.text)
+	}
+
+	if _, err := p.expect("]"); err != nil {
+		return argument{}, err
+	}
+
+	return argument{tok: t, index: i}, nil
+}
+
+func (p *parser) argList(regs map[string]register) ([]argument, error) {
+	var list []argument
+	for {
+		a, err := p.argument(regs)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, a)
+		if !p.accept(",") {
+			return list, nil
+		}
+	}
+}
+
+// resolve returns the indices of the bits referred to by a.
+func (p *parser) resolve(a argument, regs map[string]register) []int {
+	reg := regs[a.tok.text]
+	if a.index >= 0 {
+		return []int{reg.offset + a.index}
+	}
+
+	idx := make([]int, reg.size)
+	for i := range idx {
+		idx[i] = reg.offset + i
+	}
+
+	return idx
+}
+
+// broadcast returns the list of qubit operands of a gate applied to args.
+// Whole registers are expanded element-wise and must have the same size.
+func (p *parser) broadcast(args []argument, regs map[string]register) ([][]q.Qubit, error) {
+	size := 1
+	for _, a := range args {
+		if a.index >= 0 {
+			continue
+		}
+
+		n := regs[a.tok.text].size
+		if size > 1 && n != size {
+			return nil, errorf(a.tok, "size mismatch of register %q", a.tok.text)
+		}
+
+		size = n
+	}
+
+	out := make([][]q.Qubit, size)
+	for i := range size {
+		for _, a := range args {
+			idx := p.resolve(a, regs)
+			if len(idx) == 1 {
+				out[i] = append(out[i], q.Qubit(idx[0]))
+				continue
+			}
+
+			out[i] = append(out[i], q.Qubit(idx[i]))
+		}
+	}
+
+	for _, qb := range out {
+		seen := make(map[q.Qubit]bool)
+		for j, v := range qb {
+			if seen[v] {
+				return nil, errorf(args[j].tok, "duplicate qubit argument %q", args[j].tok.text)
+			}
+
+			seen[v] = true
+		}
+	}
+
+	return out, nil
+}
+
+// signature returns the number of parameters and qubits of the gate t.
+// It returns an error if t is neither a defined gate nor an available builtin.
+func (p *parser) signature(t token) (int, int, error) {
+	if def, ok := p.gates[t.text]; ok {
+		return len(def.params), len(def.args), nil
+	}
+
+	b, ok := builtins[t.text]
+	if !ok || (!b.core && !p.qelib) {
+		return 0, 0, errorf(t, "unsupported gate %q", t.text)
+	}
+
+	return b.params, b.qubits, nil
+}
+
+// apply appends the operations of the gate t to the circuit.
+func (p *parser) apply(t token, params []float64, qb []q.Qubit, cond *q.Condition) error {
+	np, nq, err := p.signature(t)
+	if err != nil {
+		return err
+	}
+
+	if len(params) != np || len(qb) != nq {
+		return errorf(t, "gate %q takes %d parameters and %d qubits", t.text, np, nq)
+	}
+
+	if def, ok := p.gates[t.text]; ok {
+		env := make(map[string]float64)
+		for i, v := range def.params {
+			env[v] = params[i]
+		}
+
+		args := make(map[string]q.Qubit)
+		for i, v := range def.args {
+			args[v] = qb[i]
+		}
+
+		for _, c := range def.body {
+			operands := make([]q.Qubit, len(c.args))
+			for i, a := range c.args {
+				operands[i] = args[a.text]
+			}
+
+			if c.tok.text == "barrier" {
+				p.circuit.Add(q.Op{Type: q.OpBarrier, Target: operands})
+				continue
+			}
+
+			values := make([]float64, len(c.params))
+			for i, e := range c.params {
+				values[i] = e(env)
+			}
+
+			if err := p.apply(c.tok, values, operands, cond); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, op := range builtins[t.text].ops(params, qb) {
+		op.Cond = cond
+		p.circuit.Add(op)
+	}
+
+	return nil
+}
+
+// exprList parses a comma-separated list of expressions terminated by ")".
+func (p *parser) exprList(env map[string]bool) ([]expr, error) {
+	var list []expr
+	for {
+		e, err := p.expr(env)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, e)
+		if p.accept(",") {
+			continue
+		}
+
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return list, nil
+	}
+}
+
+// expr parses an additive expression.
+func (p *parser) expr(env map[string]bool) (expr, error) {
+	lhs, err := p.term(env)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.accept("+"):
+			rhs, err := p.term(env)
+			if err != nil {
+				return nil, err
+			}
+
+			l := lhs
+			lhs = func(e map[string]float64) float64 { return l(e) + rhs(e) }
+		case p.accept("-"):
+			rhs, err := p.term(env)
+			if err != nil {
+				return nil, err
+			}
+
+			l := lhs
+			lhs = func(e map[string]float64) float64 { return l(e) - rhs(e) }
+		default:
+			return lhs, nil
+		}
+	}
+}
+
+// term parses a multiplicative expression.
+func (p *parser) term(env map[string]bool) (expr, error) {
+	lhs, err := p.unary(env)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.accept("*"):
+			rhs, err := p.unary(env)
+			if err != nil {
+				return nil, err
+			}
+
+			l := lhs
+			lhs = func(e map[string]float64) float64 { return l(e) * rhs(e) }
+		case p.accept("/"):
+			rhs, err := p.unary(env)
+			if err != nil {
+				return nil, err
+			}
+
+			l := lhs
+			lhs = func(e map[string]float64) float64 { return l(e) / rhs(e) }
+		default:
+			return lhs, nil
+		}
+	}
+}
+
+// unary parses a signed power expression.
+func (p *parser) unary(env map[string]bool) (expr, error) {
+	if p.accept("-") {
+		v, err := p.unary(env)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(e map[string]float64) float64 { return -v(e) }, nil
+	}
+
+	if p.accept("+") {
+		return p.unary(env)
+	}
+
+	base, err := p.primary(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.accept("^") {
+		return base, nil
+	}
+
+	exp, err := p.unary(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(e map[string]float64) float64 { return math.Pow(base(e), exp(e)) }, nil
+}
+
+var funcs = map[string]func(float64) float64{
+	"sin":  math.Sin,
+	"cos":  math.Cos,
+	"tan":  math.Tan,
+	"exp":  math.Exp,
+	"ln":   math.Log,
+	"sqrt": math.Sqrt,
+}
+
+// primary parses a number, pi, a parameter, a function call or a parenthesized expression.
+func (p *parser) primary(env map[string]bool) (expr, error) {
+	t := p.next()
+	switch {
+	case t.kind == numberKind:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errorf(t, "invalid number %q", t.text)
+		}
+
+		return func(map[string]float64) float64 { return v }, nil
+	case t.kind == symbolKind && t.text == "(":
+		e, err := p.expr(env)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return e, nil
+	case t.kind == identKind && t.text == "pi":
+		return func(map[string]float64) float64 { return math.Pi }, nil
+	case t.kind == identKind && env[t.text]:
+		name := t.text
+		return func(e map[string]float64) float64 { return e[name] }, nil
+	case t.kind == identKind && funcs[t.text] != nil:
+		f := funcs[t.text]
+		if _, err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		v, err := p.expr(env)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return func(e map[string]float64) float64 { return f(v(e)) }, nil
+	case t.kind == identKind:
+		return nil, errorf(t, "undefined parameter %q", t.text)
+	}
+
+	return nil, errorf(t, "unexpected %q in expression", t.text)
+}
@@ -0,0 +1,374 @@
+package qasm_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/qasm"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleParse() {
+	c, err := qasm.Parse(`
+		OPENQASM 2.0;
+		include "qelib1.inc";
+
+		qreg q[2];
+		creg c[2];
+
+		h q[0];
+		cx q[0], q[1];
+		measure q -> c;
+	`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(c)
+
+	// Output:
+	// H [0]
+	// ControlledX [0] [1]
+	// Measure [0] -> [0]
+	// Measure [1] -> [1]
+}
+
+func ExampleLoad() {
+	qsim, _, err := qasm.Load(`
+		OPENQASM 2.0;
+		include "qelib1.inc";
+
+		qreg q[3];
+		h q;
+	`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, s := range qsim.State() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [000] ( 0.3536 0.0000i): 0.1250
+	// [001] ( 0.3536 0.0000i): 0.1250
+	// [010] ( 0.3536 0.0000i): 0.1250
+	// [011] ( 0.3536 0.0000i): 0.1250
+	// [100] ( 0.3536 0.0000i): 0.1250
+	// [101] ( 0.3536 0.0000i): 0.1250
+	// [110] ( 0.3536 0.0000i): 0.1250
+	// [111] ( 0.3536 0.0000i): 0.1250
+}
+
+func ExampleLoad_quantumTeleportation() {
+	qsim, _, err := qasm.Load(`
+		OPENQASM 2.0;
+		include "qelib1.inc";
+
+		qreg q[3];
+		creg c0[1];
+		creg c1[1];
+
+		// |psi> = 0.4472|0> + 0.8944|1>
+		ry(2.2142974355881813) q[0];
+
+		h q[1];
+		cx q[1], q[2];
+		barrier q;
+
+		cx q[0], q[1];
+		h q[0];
+		measure q[0] -> c0[0];
+		measure q[1] -> c1[0];
+
+		if(c1==1) x q[2];
+		if(c0==1) z q[2];
+	`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, s := range qsim.State(q.Qubit(2)) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [0] ( 0.4472 0.0000i): 0.2000
+	// [1] ( 0.8944 0.0000i): 0.8000
+}
+
+func ExampleLoad_gate() {
+	qsim, bits, err := qasm.Load(`
+		OPENQASM 2.0;
+		include "qelib1.inc";
+
+		gate bell a, b {
+			h a;
+			cx a, b;
+		}
+
+		gate rot(theta) a {
+			ry(theta/2) a;
+			ry(theta/2) a;
+		}
+
+		qreg q[2];
+		qreg r[1];
+		creg c[1];
+
+		rot(pi) r[0];
+		measure r[0] -> c[0];
+		if(c==1) bell q[0], q[1];
+	`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(bits)
+	for _, s := range qsim.State() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [1]
+	// [001] ( 0.7071 0.0000i): 0.5000
+	// [111] ( 0.7071 0.0000i): 0.5000
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		src  string
+		want func(qsim *q.Q)
+	}{
+		{
+			src: `qreg q[1]; U(pi, 0, pi) q[0];`,
+			want: func(qsim *q.Q) {
+				qsim.X(qsim.Zero())
+			},
+		},
+		{
+			src: `qreg q[2]; U(pi/2, 0, pi) q[0]; CX q[0], q[1];`,
+			want: func(qsim *q.Q) {
+				qb := qsim.Zeros(2)
+				qsim.H(qb[0]).CNOT(qb[0], qb[1])
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[1]; u2(0, pi) q[0]; u1(pi/4) q[0]; p(pi/8) q[0]; u0(1) q[0]; id q[0];`,
+			want: func(qsim *q.Q) {
+				q0 := qsim.Zero()
+				qsim.H(q0).T(q0).R(math.Pi/8, q0)
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[1]; h q[0]; s q[0]; sdg q[0]; t q[0]; tdg q[0]; x q[0]; y q[0]; z q[0];`,
+			want: func(qsim *q.Q) {
+				q0 := qsim.Zero()
+				qsim.H(q0).X(q0).Y(q0).Z(q0)
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[1]; sx q[0]; sx q[0]; sxdg q[0];`,
+			want: func(qsim *q.Q) {
+				q0 := qsim.Zero()
+				qsim.X(q0)
+				qsim.G(gate.U(-math.Pi/2, -math.Pi/2, math.Pi/2).Mul(complex(math.Sqrt2/2, -math.Sqrt2/2)), q0)
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[1]; rx(0.1) q[0]; ry(0.2) q[0]; rz(-0.3) q[0]; u3(1e-1, 2.0, .3) q[0];`,
+			want: func(qsim *q.Q) {
+				q0 := qsim.Zero()
+				qsim.RX(0.1, q0).RY(0.2, q0).RZ(-0.3, q0).U(0.1, 2.0, 0.3, q0)
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[3]; h q; cy q[0], q[1]; cz q[1], q[2]; ch q[2], q[0]; csx q[0], q[2];`,
+			want: func(qsim *q.Q) {
+				qb := qsim.Zeros(3)
+				qsim.H(qb...)
+				qsim.C(gate.Y(), qb[0], qb[1])
+				qsim.CZ(qb[1], qb[2])
+				qsim.ControlledH([]q.Qubit{qb[2]}, []q.Qubit{qb[0]})
+				qsim.C(gate.RX(math.Pi/2).Mul(complex(math.Sqrt2/2, math.Sqrt2/2)), qb[0], qb[2])
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[3]; h q; crx(0.1) q[0], q[1]; cry(0.2) q[1], q[2]; crz(0.3) q[2], q[0]; cu1(0.4) q[0], q[1]; cp(0.5) q[1], q[2]; cu3(0.6, 0.7, 0.8) q[2], q[0];`,
+			want: func(qsim *q.Q) {
+				qb := qsim.Zeros(3)
+				qsim.H(qb...)
+				qsim.C(gate.RX(0.1), qb[0], qb[1])
+				qsim.C(gate.RY(0.2), qb[1], qb[2])
+				qsim.C(gate.RZ(0.3), qb[2], qb[0])
+				qsim.CR(0.4, qb[0], qb[1])
+				qsim.CR(0.5, qb[1], qb[2])
+				qsim.CU(0.6, 0.7, 0.8, qb[2], qb[0])
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[3]; x q[0]; x q[2]; cswap q[0], q[1], q[2]; ccx q[0], q[1], q[2];`,
+			want: func(qsim *q.Q) {
+				qsim.One()
+				qsim.One()
+				qsim.Zero()
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg a[2]; qreg b[2]; x a[0]; swap a, b; cx b, a;`,
+			want: func(qsim *q.Q) {
+				qsim.One()
+				qsim.Zero()
+				qsim.One()
+				qsim.Zero()
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[2]; h q; rzz(0.3) q[0], q[1]; cu(0.1, 0.2, 0.3, 0.4) q[1], q[0];`,
+			want: func(qsim *q.Q) {
+				qb := qsim.Zeros(2)
+				qsim.H(qb...)
+				qsim.CNOT(qb[0], qb[1]).R(0.3, qb[1]).CNOT(qb[0], qb[1])
+				qsim.C(gate.U(0.1, 0.2, 0.3).Mul(complex(math.Cos(0.4), math.Sin(0.4))), qb[1], qb[0])
+			},
+		},
+		{
+			src: `include "qelib1.inc"; qreg q[1]; creg c[1]; x q[0]; measure q[0] -> c[0]; reset q[0]; if(c==0) x q[0];`,
+			want: func(qsim *q.Q) {
+				qsim.Zero()
+			},
+		},
+		{
+			src: `include "qelib1.inc"; gate g(a, b) x { rz(sin(a)^2 + cos(a)^2 - sqrt(4) / 2 + exp(0) * ln(1) - tan(0)) x; rx(-b) x; } qreg q[1]; g(pi, -(0.5)) q[0];`,
+			want: func(qsim *q.Q) {
+				qsim.RX(0.5, qsim.Zero())
+			},
+		},
+		{
+			src: `include "qelib1.inc"; gate g() a, b { barrier a, b; } qreg q[2]; g q[0], q[1];`,
+			want: func(qsim *q.Q) {
+				qsim.Zeros(2)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		got, _, err := qasm.Load(c.src)
+		if err != nil {
+			t.Errorf("src=%v, err=%v", c.src, err)
+			continue
+		}
+
+		want := q.New()
+		c.want(want)
+
+		if !qubit.EqualUpToGlobalPhase(got.State(), want.State()) {
+			t.Errorf("src=%v, got=%v, want=%v", c.src, got.State(), want.State())
+		}
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{"OPENQASM 4.0;", `1:10: unsupported version "4.0"`},
+		{`include "foo.inc";`, `1:9: unsupported include "foo.inc"`},
+		{`include "qelib1.inc`, `1:9: unterminated string`},
+		{"qreg q[2];\nh q[0];", `2:1: unsupported gate "h"`},
+		{"qreg q[2];\nqreg q[1];", `2:6: register "q" is already declared`},
+		{"qreg q[0];", `1:6: register "q" must have a positive size`},
+		{"qreg q[2];\nCX q[0], q[2];", `2:12: index 2 out of range for register "q"`},
+		{"qreg q[2];\nCX q[0], q[0];", `2:10: duplicate qubit argument "q"`},
+		{"qreg q[2];\nqreg r[3];\nCX q, r;", `3:7: size mismatch of register "r"`},
+		{"qreg q[2];\nCX q[0], r[0];", `2:10: undefined register "r"`},
+		{"qreg q[2];\nU(0, 0) q[0];", `2:1: gate "U" takes 3 parameters and 1 qubits`},
+		{"qreg q[2];\nU(0, 0, foo) q[0];", `2:9: undefined parameter "foo"`},
+		{"qreg q[2];\nU(0, 0, 0) q[0]", `2:16: expected ";", found ""`},
+		{"qreg q[2];\nU(0, 0, *) q[0];", `2:9: unexpected "*" in expression`},
+		{"opaque g a;", `1:1: opaque gates are not supported`},
+		{"gate g a { U(0, 0, 0) b; }", `1:23: undefined argument "b"`},
+		{"gate g a { }\ngate g a { }", `2:6: gate "g" is already defined`},
+		{"gate g a { g a; }\nqreg q[1];\ng q[0];", `1:12: unsupported gate "g"`},
+		{"gate f a { g a; }\ngate g a { U(0, 0, 0) a; }", `1:12: unsupported gate "g"`},
+		{"gate g a { CX a; }", `1:12: gate "CX" takes 0 parameters and 2 qubits`},
+		{"gate g a { U(0) a; }", `1:12: gate "U" takes 3 parameters and 1 qubits`},
+		{"gate f a, b { CX a, b; }\ngate g a { f a; }", `2:12: gate "f" takes 0 parameters and 2 qubits`},
+		{"gate g a { h a; }", `1:12: unsupported gate "h"`},
+		{"qreg q[1];\nif(c==1) U(0, 0, 0) q[0];", `2:4: undefined classical register "c"`},
+		{"qreg q[1]; creg c[1];\nif(c==1) barrier q;", `2:10: barrier cannot be conditioned`},
+		{"qreg q[2]; creg c[1];\nmeasure q -> c;", `2:9: size mismatch between "q" and "c"`},
+		{"qreg q[1];\nq[0] = 1;", `2:2: expected identifier, found "["`},
+		{"qreg q[1];\n@", `2:1: unexpected "@"`},
+		{"qreg q[1];\n$", `2:1: unexpected character '$'`},
+		{"1;", `1:1: unexpected "1"`},
+		{"qubit[0] q;", `1:7: register size must be positive`},
+		{"qubit q;\nbit q;", `2:5: register "q" is already declared`},
+		{"qubit q; bit[2] c;\nif (c[0] == 2) x q;", `2:13: value 2 out of range for "c"`},
+		{"qubit q; bit[2] c;\nif (c == 1 && d == 1) x q;", `2:15: undefined classical register "d"`},
+		{"qubit q; bit c;\nc = reset q;", `2:5: expected "measure", found "reset"`},
+		{"qubit[2] q;\nctrl @ measure q[0];", `2:8: measure cannot be controlled`},
+		{"qubit[2] q;\nctrl(0) @ U(0, 0, 0) q[0], q[1];", `2:6: number of controls must be positive`},
+		{"qubit[2] q;\nctrl @ U(0, 0, 0) q[0];", `2:8: gate "U" takes 3 parameters and 2 qubits`},
+		{"include \"stdgates.inc\";\nqubit[2] q;\nctrl @ y q[0], q[1];", `3:8: unsupported controlled gate "y"`},
+		{"qubit[2] q;\ngphase;", `2:1: gate "gphase" takes 1 parameters and 0 qubits`},
+	}
+
+	for _, c := range cases {
+		_, err := qasm.Parse(c.src)
+
+		var e *qasm.Error
+		if !errors.As(err, &e) {
+			t.Errorf("src=%q, err=%v", c.src, err)
+			continue
+		}
+
+		if e.Error() != c.want {
+			t.Errorf("src=%q, got=%v, want=%v", c.src, e.Error(), c.want)
+		}
+	}
+}
+
+func TestLoad_measure(t *testing.T) {
+	src := `
+		OPENQASM 2.0;
+		include "qelib1.inc";
+
+		qreg q[2];
+		creg c[2];
+
+		h q[0];
+		cx q[0], q[1];
+		measure q -> c;
+		if(c==3) x q;
+	`
+
+	for range 10 {
+		c, err := qasm.Parse(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		qsim := q.New()
+		qsim.SetRand(rand.Const())
+		bits := qsim.Run(c)
+
+		if bits[0] != bits[1] {
+			t.Errorf("bits=%v", bits)
+		}
+
+		if qsim.Probability()[0] != 1 {
+			t.Errorf("state=%v", qsim.State())
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package channel
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/itsubaki/q/math/matrix"
@@ -160,3 +161,62 @@ func PhaseDamping(gamma float64, qb int) ChannelFunc {
 		return New(k0, k1)
 	}
 }
+
+// ThermalRelaxation returns a new quantum channel that applies a thermal relaxation channel to the specified qubit.
+// t1 and t2 are the relaxation and dephasing times, and time is the duration in the same unit.
+// excited is the excited-state population at equilibrium, which is zero at zero temperature.
+// It returns an error if the parameters are out of range or t2 is greater than 2*t1.
+func ThermalRelaxation(t1, t2, time, excited float64, qb int) (ChannelFunc, error) {
+	if t1 <= 0 || t2 <= 0 {
+		return nil, fmt.Errorf("invalid t1=%v, t2=%v: must be positive", t1, t2)
+	}
+
+	if t2 > 2*t1 {
+		return nil, fmt.Errorf("invalid t2=%v: must be less than or equal to 2*t1=%v", t2, 2*t1)
+	}
+
+	if time < 0 {
+		return nil, fmt.Errorf("invalid time=%v: must be non-negative", time)
+	}
+
+	if excited < 0 || excited > 1 {
+		return nil, fmt.Errorf("invalid excited=%v: must be in [0, 1]", excited)
+	}
+
+	// the probability of the relaxation and the rate of the pure dephasing.
+	p := 1 - math.Exp(-time/t1)
+	rate := 1/t2 - 1/(2*t1)
+	gamma := 1 - math.Exp(-2*time*rate)
+
+	g0 := complex(math.Sqrt(1-excited), 0)
+	g1 := complex(math.Sqrt(excited), 0)
+	sp := complex(math.Sqrt(p), 0)
+	sq := complex(math.Sqrt(1-p), 0)
+
+	// generalized amplitude damping
+	e := []*matrix.Matrix{
+		matrix.New([]complex128{g0, 0}, []complex128{0, g0 * sq}),
+		matrix.New([]complex128{0, g0 * sp}, []complex128{0, 0}),
+	}
+
+	if excited > 0 {
+		e = append(e,
+			matrix.New([]complex128{g1 * sq, 0}, []complex128{0, g1}),
+			matrix.New([]complex128{0, 0}, []complex128{g1 * sp, 0}),
+		)
+	}
+
+	local := New(e...)
+	if gamma > 0 {
+		local = local.Compose(PhaseDamping(gamma, 0)(1))
+	}
+
+	return func(n int) *Channel {
+		kraus := make([]*matrix.Matrix, len(local.Kraus))
+		for i, k := range local.Kraus {
+			kraus[i] = gate.TensorProduct(k, n, []int{qb})
+		}
+
+		return New(kraus...)
+	}, nil
+}
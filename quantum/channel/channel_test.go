@@ -116,3 +116,36 @@ func FuzzPauli(f *testing.F) {
 		}
 	})
 }
+
+func TestThermalRelaxation(t *testing.T) {
+	cases := []struct {
+		t1, t2, time, excited float64
+		isErr                 bool
+	}{
+		{100, 80, 10, 0, false},
+		{100, 200, 10, 0, false},
+		{100, 50, 0, 0.1, false},
+		{100, 50, 1e6, 0.2, false},
+		{50, 30, 10, 1, false},
+		{100, 201, 10, 0, true},
+		{0, 50, 10, 0, true},
+		{100, -1, 10, 0, true},
+		{100, 80, -1, 0, true},
+		{100, 80, 10, 1.1, true},
+	}
+
+	for _, c := range cases {
+		fn, err := channel.ThermalRelaxation(c.t1, c.t2, c.time, c.excited, 0)
+		if (err != nil) != c.isErr {
+			t.Errorf("t1=%v t2=%v time=%v excited=%v: err=%v", c.t1, c.t2, c.time, c.excited, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !fn(2).IsTracePreserving() {
+			t.Errorf("t1=%v t2=%v time=%v excited=%v", c.t1, c.t2, c.time, c.excited)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package channel
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/itsubaki/q/math/eigen"
+	"github.com/itsubaki/q/math/matrix"
+)
+
+// ProcessFidelity returns the process fidelity between the channels.
+// It is the fidelity between the normalized Choi matrices, and one if the channels are the same.
+// A unitary u is compared as New(u).
+func ProcessFidelity(a, b *Channel, tol ...float64) float64 {
+	d := float64(a.Dim())
+	if len(b.Kraus) == 1 {
+		a, b = b, a
+	}
+
+	if len(a.Kraus) == 1 {
+		// <psi|J_b|psi>/d^2 for the pure Choi matrix |psi><psi| of a.
+		psi, j := choiVector(a.Kraus[0]), b.Choi()
+
+		var sum complex128
+		for x := range psi {
+			for y := range psi {
+				sum += cmplx.Conj(psi[x]) * j.At(x, y) * psi[y]
+			}
+		}
+
+		return real(sum) / (d * d)
+	}
+
+	sa := sqrt(a.Choi(), tol...)
+	m := matrix.MatMul(sa, b.Choi(), sa)
+
+	var sum float64
+	_, lambdas := eigen.Jacobi(m, eigen.Iterations(m), tol...)
+	for i := range lambdas.Rows {
+		sum += math.Sqrt(max(real(lambdas.At(i, i)), 0))
+	}
+
+	return sum * sum / (d * d)
+}
+
+// AverageGateFidelity returns the fidelity between the output states of the channels averaged over the pure input states.
+// It is (d*F + 1)/(d + 1) of the process fidelity F, which holds if a is trace-preserving and b is unitary.
+func AverageGateFidelity(a, b *Channel, tol ...float64) float64 {
+	d := float64(a.Dim())
+	return (d*ProcessFidelity(a, b, tol...) + 1) / (d + 1)
+}
+
+// EntanglementFidelity returns the entanglement fidelity between the channels.
+// It is the fidelity between the output states of the maximally entangled state, and equals the process fidelity.
+// If b is the unitary v, it is sum_k |Tr v^dagger K_k|^2 / d^2 of the Kraus operators K_k of a.
+func EntanglementFidelity(a, b *Channel, tol ...float64) float64 {
+	if len(a.Kraus) == 1 && len(b.Kraus) != 1 {
+		a, b = b, a
+	}
+
+	if len(b.Kraus) != 1 {
+		return ProcessFidelity(a, b, tol...)
+	}
+
+	d := float64(a.Dim())
+	v := b.Kraus[0].Dagger()
+
+	var sum float64
+	for _, k := range a.Kraus {
+		sum += math.Pow(cmplx.Abs(matrix.MatMul(v, k).Trace()), 2)
+	}
+
+	return sum / (d * d)
+}
+
+// DiamondNorm returns the lower and upper bounds of the diamond norm of the difference of the channels.
+// The bounds are ||J||_1/d and ||J||_1 of the Choi matrix J of the difference.
+// The lower bound is the diamond norm for the Pauli channels.
+func DiamondNorm(a, b *Channel, tol ...float64) (lower, upper float64) {
+	j := a.Choi().Sub(b.Choi())
+
+	var norm float64
+	_, lambdas := eigen.Jacobi(j, eigen.Iterations(j), tol...)
+	for i := range lambdas.Rows {
+		norm += math.Abs(real(lambdas.At(i, i)))
+	}
+
+	return norm / float64(a.Dim()), norm
+}
+
+// choiVector returns the vector |psi> of the Choi matrix |psi><psi| of the operator k.
+func choiVector(k *matrix.Matrix) []complex128 {
+	d := k.Rows
+
+	out := make([]complex128, d*d)
+	for i := range d {
+		for a := range d {
+			out[i*d+a] = k.At(a, i)
+		}
+	}
+
+	return out
+}
+
+// sqrt returns the square root of the Hermitian positive semidefinite matrix m.
+func sqrt(m *matrix.Matrix, tol ...float64) *matrix.Matrix {
+	v, d := eigen.Jacobi(m, eigen.Iterations(m), tol...)
+	d.Fdiag(func(z complex128) complex128 {
+		return complex(math.Sqrt(max(real(z), 0)), 0)
+	})
+
+	return matrix.MatMul(v, d, v.Dagger())
+}
@@ -0,0 +1,100 @@
+package channel_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/quantum/channel"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+func ExampleProcessFidelity() {
+	noisy := channel.Depolarizing(0.3, 0)(1)
+	ideal := channel.New(gate.I())
+
+	lower, upper := channel.DiamondNorm(noisy, ideal)
+	fmt.Printf("%.4f\n", channel.ProcessFidelity(noisy, ideal))
+	fmt.Printf("%.4f\n", channel.AverageGateFidelity(noisy, ideal))
+	fmt.Printf("%.4f\n", channel.EntanglementFidelity(noisy, ideal))
+	fmt.Printf("%.4f %.4f\n", lower, upper)
+
+	// Output:
+	// 0.7000
+	// 0.8000
+	// 0.7000
+	// 0.6000 1.2000
+}
+
+func TestProcessFidelity(t *testing.T) {
+	h := gate.H()
+	half := h.Mul(complex(math.Sqrt(0.5), 0))
+	ad := channel.AmplitudeDamping(0.3, 0)(1)
+
+	cases := []struct {
+		a, b *channel.Channel
+		want float64
+	}{
+		{channel.New(h), channel.New(h), 1},
+		{channel.New(h), channel.New(gate.X()), 0.5},
+		{channel.New(half, half), channel.New(gate.X()), 0.5},
+		{channel.New(half, half), channel.New(half, half), 1},
+		{ad, ad, 1},
+		{ad, channel.New(gate.I()), math.Pow(1+math.Sqrt(0.7), 2) / 4},
+		{channel.New(gate.I()), ad, math.Pow(1+math.Sqrt(0.7), 2) / 4},
+		{channel.Depolarizing(0.2, 0)(2), channel.New(gate.I(2)), 0.8},
+		{channel.New(gate.CNOT(2, 0, 1)), channel.New(gate.CNOT(2, 1, 0)), 0.0625},
+	}
+
+	for _, c := range cases {
+		if got := channel.ProcessFidelity(c.a, c.b); !epsilon.IsCloseF64(got, c.want, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, c.want)
+		}
+	}
+}
+
+func TestEntanglementFidelity(t *testing.T) {
+	h := gate.H()
+	half := h.Mul(complex(math.Sqrt(0.5), 0))
+	ad := channel.AmplitudeDamping(0.3, 0)(1)
+
+	cases := []struct {
+		a, b *channel.Channel
+	}{
+		{channel.New(h), channel.New(h)},
+		{channel.New(h), channel.New(gate.X())},
+		{channel.New(half, half), channel.New(gate.X())},
+		{channel.New(gate.X()), channel.New(half, half)},
+		{ad, ad},
+		{ad, channel.New(gate.I())},
+		{ad, channel.New(gate.Z())},
+		{channel.Depolarizing(0.2, 0)(2), channel.New(gate.CNOT(2, 0, 1))},
+	}
+
+	for _, c := range cases {
+		want := channel.ProcessFidelity(c.a, c.b)
+		if got := channel.EntanglementFidelity(c.a, c.b); !epsilon.IsCloseF64(got, want, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, want)
+		}
+	}
+}
+
+func TestDiamondNorm(t *testing.T) {
+	cases := []struct {
+		a, b         *channel.Channel
+		lower, upper float64
+	}{
+		{channel.New(gate.H()), channel.New(gate.H()), 0, 0},
+		{channel.Pauli(0.1, 0.05, 0.02, 0)(1), channel.New(gate.I()), 0.34, 0.68},
+		{channel.BitFlip(0.5, 0)(1), channel.New(gate.X()), 1, 2},
+		{channel.New(gate.I()), channel.New(gate.X()), 2, 4},
+	}
+
+	for _, c := range cases {
+		lower, upper := channel.DiamondNorm(c.a, c.b)
+		if !epsilon.IsCloseF64(lower, c.lower, 1e-6) || !epsilon.IsCloseF64(upper, c.upper, 1e-6) {
+			t.Errorf("got=(%v, %v), want=(%v, %v)", lower, upper, c.lower, c.upper)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package channel
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strings"
+
+	"github.com/itsubaki/q/math/eigen"
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/quantum/observable"
+)
+
+// Dim returns the dimension of the Hilbert space that the channel acts on.
+func (c *Channel) Dim() int {
+	return c.Kraus[0].Rows
+}
+
+// IsUnital returns true if the channel maps the identity to the identity.
+func (c *Channel) IsUnital(tol ...float64) bool {
+	sum := matrix.ZeroLike(c.Kraus[0])
+	for _, k := range c.Kraus {
+		sum = sum.Add(matrix.MatMul(k, k.Dagger()))
+	}
+
+	return sum.IsIdentity(tol...)
+}
+
+// Choi returns the Choi matrix sum_ij |i><j| x E(|i><j|) of the channel.
+// The trace of the Choi matrix is the dimension for a trace-preserving channel.
+func (c *Channel) Choi() *matrix.Matrix {
+	d := c.Dim()
+
+	out := matrix.Zero(d*d, d*d)
+	for _, k := range c.Kraus {
+		for i := range d {
+			for a := range d {
+				for j := range d {
+					for b := range d {
+						out.AddAt(i*d+a, j*d+b, k.At(a, i)*cmplx.Conj(k.At(b, j)))
+					}
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// Superoperator returns the Liouville superoperator sum_k K_k x conj(K_k) of the channel.
+// It acts on the density matrix vectorized in row-major order.
+func (c *Channel) Superoperator() *matrix.Matrix {
+	d := c.Dim()
+
+	out := matrix.Zero(d*d, d*d)
+	for _, k := range c.Kraus {
+		out = out.Add(matrix.TensorProduct(k, k.Conjugate()))
+	}
+
+	return out
+}
+
+// PTM returns the Pauli transfer matrix of the channel.
+// The (i, j) element is Tr[P_i E(P_j)]/d, where P_i is the i-th Pauli string in the order of I, X, Y and Z.
+func (c *Channel) PTM() *matrix.Matrix {
+	d := c.Dim()
+	p := pauli(number.Log2(d))
+
+	out := matrix.Zero(len(p), len(p))
+	for j := range p {
+		e := matrix.ZeroLike(p[j])
+		for _, k := range c.Kraus {
+			e = e.Add(matrix.MatMul(k, p[j], k.Dagger()))
+		}
+
+		for i := range p {
+			tr := matrix.MatMul(p[i], e).Trace()
+			out.Set(i, j, complex(real(tr)/float64(d), 0))
+		}
+	}
+
+	return out
+}
+
+// Chi returns the chi matrix of the channel in the Pauli basis.
+// The channel is E(rho) = sum_mn chi_mn P_m rho P_n.
+func (c *Channel) Chi() *matrix.Matrix {
+	d := c.Dim()
+	p := pauli(number.Log2(d))
+
+	out := matrix.Zero(len(p), len(p))
+	for _, k := range c.Kraus {
+		coef := make([]complex128, len(p))
+		for m := range p {
+			coef[m] = matrix.MatMul(p[m], k).Trace() / complex(float64(d), 0)
+		}
+
+		for m := range p {
+			for n := range p {
+				out.AddAt(m, n, coef[m]*cmplx.Conj(coef[n]))
+			}
+		}
+	}
+
+	return out
+}
+
+// Stinespring returns the Stinespring operator V = sum_k K_k x |k> of the channel.
+// The environment is the least significant part of the output, and V is an isometry for a trace-preserving channel.
+func (c *Channel) Stinespring() *matrix.Matrix {
+	d, r := c.Dim(), len(c.Kraus)
+
+	out := matrix.Zero(d*r, d)
+	for k := range c.Kraus {
+		for a := range d {
+			for i := range d {
+				out.Set(a*r+k, i, c.Kraus[k].At(a, i))
+			}
+		}
+	}
+
+	return out
+}
+
+// IsCompletelyPositive returns true if the Choi matrix is Hermitian and positive semidefinite.
+func IsCompletelyPositive(choi *matrix.Matrix, tol ...float64) bool {
+	if !choi.IsHermitian(tol...) {
+		return false
+	}
+
+	atol, _ := epsilon.Tol(tol...)
+	_, lambdas := eigen.Jacobi(choi, eigen.Iterations(choi), tol...)
+	for i := range lambdas.Rows {
+		if real(lambdas.At(i, i)) < -atol {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FromChoi returns the channel with the minimal Kraus operators of the Choi matrix.
+// It returns an error if the Choi matrix is not completely positive.
+func FromChoi(choi *matrix.Matrix, tol ...float64) (*Channel, error) {
+	if !IsCompletelyPositive(choi, tol...) {
+		return nil, fmt.Errorf("the choi matrix is not completely positive")
+	}
+
+	d := int(math.Sqrt(float64(choi.Rows)))
+	vectors, lambdas := eigen.Jacobi(choi, eigen.Iterations(choi), tol...)
+
+	atol, _ := epsilon.Tol(tol...)
+	var kraus []*matrix.Matrix
+	for v := range lambdas.Rows {
+		lambda := real(lambdas.At(v, v))
+		if lambda <= atol {
+			continue
+		}
+
+		s := complex(math.Sqrt(lambda), 0)
+		k := matrix.Zero(d, d)
+		for i := range d {
+			for a := range d {
+				k.Set(a, i, s*vectors.At(i*d+a, v))
+			}
+		}
+
+		kraus = append(kraus, k)
+	}
+
+	return New(kraus...), nil
+}
+
+// FromSuperoperator returns the channel with the minimal Kraus operators of the Liouville superoperator.
+// It returns an error if the superoperator is not completely positive.
+func FromSuperoperator(s *matrix.Matrix, tol ...float64) (*Channel, error) {
+	d := int(math.Sqrt(float64(s.Rows)))
+
+	// reshuffle the superoperator into the Choi matrix.
+	choi := matrix.Zero(d*d, d*d)
+	for a := range d {
+		for b := range d {
+			for i := range d {
+				for j := range d {
+					choi.Set(i*d+a, j*d+b, s.At(a*d+b, i*d+j))
+				}
+			}
+		}
+	}
+
+	return FromChoi(choi, tol...)
+}
+
+// FromPTM returns the channel with the minimal Kraus operators of the Pauli transfer matrix.
+// It returns an error if the Pauli transfer matrix is not completely positive.
+func FromPTM(r *matrix.Matrix, tol ...float64) (*Channel, error) {
+	p := pauli(number.Log2(r.Rows) / 2)
+	d := p[0].Rows
+
+	// S = sum_ij R_ij |P_i>><<P_j| / d
+	s := matrix.Zero(d*d, d*d)
+	for i := range p {
+		for j := range p {
+			rij := r.At(i, j) / complex(float64(d), 0)
+			if rij == 0 {
+				continue
+			}
+
+			for x := range d * d {
+				for y := range d * d {
+					s.AddAt(x, y, rij*p[i].Data[x]*cmplx.Conj(p[j].Data[y]))
+				}
+			}
+		}
+	}
+
+	return FromSuperoperator(s, tol...)
+}
+
+// FromChi returns the channel with the minimal Kraus operators of the chi matrix in the Pauli basis.
+// It returns an error if the chi matrix is not positive semidefinite.
+func FromChi(chi *matrix.Matrix, tol ...float64) (*Channel, error) {
+	if !IsCompletelyPositive(chi, tol...) {
+		return nil, fmt.Errorf("the chi matrix is not positive semidefinite")
+	}
+
+	p := pauli(number.Log2(chi.Rows) / 2)
+	vectors, lambdas := eigen.Jacobi(chi, eigen.Iterations(chi), tol...)
+
+	atol, _ := epsilon.Tol(tol...)
+	var kraus []*matrix.Matrix
+	for v := range lambdas.Rows {
+		lambda := real(lambdas.At(v, v))
+		if lambda <= atol {
+			continue
+		}
+
+		s := complex(math.Sqrt(lambda), 0)
+		k := matrix.ZeroLike(p[0])
+		for m := range p {
+			k = k.Add(p[m].Mul(s * vectors.At(m, v)))
+		}
+
+		kraus = append(kraus, k)
+	}
+
+	return New(kraus...), nil
+}
+
+// FromStinespring returns the channel of the Stinespring operator.
+// The environment is the least significant part of the output.
+func FromStinespring(v *matrix.Matrix) *Channel {
+	d := v.Cols
+	r := v.Rows / d
+
+	kraus := make([]*matrix.Matrix, r)
+	for k := range r {
+		kraus[k] = matrix.Zero(d, d)
+		for a := range d {
+			for i := range d {
+				kraus[k].Set(a, i, v.At(a*r+k, i))
+			}
+		}
+	}
+
+	return New(kraus...)
+}
+
+// pauli returns the n-qubit Pauli strings in the order of I, X, Y and Z.
+func pauli(n int) []*matrix.Matrix {
+	out := make([]*matrix.Matrix, 1<<(2*n))
+	for i := range out {
+		var sb strings.Builder
+		for j := n - 1; j >= 0; j-- {
+			sb.WriteByte("IXYZ"[(i>>(2*j))&3])
+		}
+
+		out[i] = observable.Pauli(sb.String())
+	}
+
+	return out
+}
@@ -0,0 +1,154 @@
+package channel_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/quantum/channel"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+func ExampleChannel_PTM() {
+	ch := channel.Depolarizing(0.3, 0)(1)
+	for _, r := range ch.PTM().Real() {
+		fmt.Printf("%.2f\n", r)
+	}
+
+	// Output:
+	// [1.00 0.00 0.00 0.00]
+	// [0.00 0.60 0.00 0.00]
+	// [0.00 0.00 0.60 0.00]
+	// [0.00 0.00 0.00 0.60]
+}
+
+func ExampleFromChoi() {
+	// the composition of the amplitude damping channels has four Kraus operators.
+	ch := channel.ComposeFunc(
+		channel.AmplitudeDamping(0.3, 0),
+		channel.AmplitudeDamping(0.5, 0),
+	)(1)
+
+	kraus, err := channel.FromChoi(ch.Choi())
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(len(ch.Kraus), len(kraus.Kraus))
+	fmt.Println(kraus.Choi().Equal(ch.Choi()))
+	fmt.Println(kraus.IsTracePreserving())
+
+	// Output:
+	// 4 2
+	// true
+	// true
+}
+
+func TestFrom(t *testing.T) {
+	cases := []struct {
+		channel *channel.Channel
+		unital  bool
+	}{
+		{channel.Depolarizing(0.2, 0)(1), true},
+		{channel.Pauli(0.1, 0.2, 0.3, 0)(1), true},
+		{channel.AmplitudeDamping(0.4, 0)(1), false},
+		{channel.PhaseDamping(0.4, 0)(1), true},
+		{number.Must(channel.ThermalRelaxation(100, 80, 20, 0.1, 0))(1), false},
+		{channel.New(gate.H()), true},
+		{channel.New(gate.CNOT(2, 0, 1)), true},
+		{channel.ComposeFunc(channel.BitFlip(0.1, 0), channel.AmplitudeDamping(0.3, 1))(2), false},
+	}
+
+	for _, c := range cases {
+		if !c.channel.IsTracePreserving() {
+			t.Errorf("channel=%v is not trace-preserving", c.channel)
+		}
+
+		if c.channel.IsUnital() != c.unital {
+			t.Errorf("got=%v, want=%v", c.channel.IsUnital(), c.unital)
+		}
+
+		choi := c.channel.Choi()
+		if !channel.IsCompletelyPositive(choi) {
+			t.Errorf("choi=%v is not completely positive", choi)
+		}
+
+		for _, from := range []func() (*channel.Channel, error){
+			func() (*channel.Channel, error) { return channel.FromChoi(choi) },
+			func() (*channel.Channel, error) { return channel.FromSuperoperator(c.channel.Superoperator()) },
+			func() (*channel.Channel, error) { return channel.FromPTM(c.channel.PTM()) },
+			func() (*channel.Channel, error) { return channel.FromChi(c.channel.Chi()) },
+			func() (*channel.Channel, error) { return channel.FromStinespring(c.channel.Stinespring()), nil },
+		} {
+			got, err := from()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Choi().Equal(choi) {
+				t.Errorf("got=%v, want=%v", got.Choi(), choi)
+			}
+		}
+	}
+}
+
+func TestChannel_Superoperator(t *testing.T) {
+	ch := channel.ComposeFunc(channel.Depolarizing(0.2, 0), channel.AmplitudeDamping(0.3, 1))(2)
+	rho := matrix.New(
+		[]complex128{0.4, 0.1, 0, 0.2i},
+		[]complex128{0.1, 0.3, 0, 0},
+		[]complex128{0, 0, 0.2, 0},
+		[]complex128{-0.2i, 0, 0, 0.1},
+	)
+
+	want := matrix.ZeroLike(rho)
+	for _, k := range ch.Kraus {
+		want = want.Add(matrix.MatMul(k, rho, k.Dagger()))
+	}
+
+	// the superoperator acts on the row-major vectorization of rho.
+	got := matrix.Zero(4, 4)
+	s := ch.Superoperator()
+	for i := range 16 {
+		for j := range 16 {
+			got.Data[i] += s.At(i, j) * rho.Data[j]
+		}
+	}
+
+	if !got.Equal(want) {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestChannel_Stinespring(t *testing.T) {
+	cases := []struct {
+		channel *channel.Channel
+	}{
+		{channel.Depolarizing(0.2, 0)(1)},
+		{channel.AmplitudeDamping(0.4, 0)(2)},
+	}
+
+	for _, c := range cases {
+		v := c.channel.Stinespring()
+		if !matrix.MatMul(v.Dagger(), v).IsIdentity() {
+			t.Errorf("v=%v is not an isometry", v)
+		}
+	}
+}
+
+func TestIsCompletelyPositive(t *testing.T) {
+	// the choi matrix of the transpose map is the swap operator.
+	transpose := gate.Swap(2, 0, 1)
+	if channel.IsCompletelyPositive(transpose) {
+		t.Errorf("transpose is completely positive")
+	}
+
+	if _, err := channel.FromChoi(transpose); err == nil {
+		t.Errorf("err is nil")
+	}
+
+	if _, err := channel.FromChi(gate.Z(2)); err == nil {
+		t.Errorf("err is nil")
+	}
+}
@@ -0,0 +1,137 @@
+package density
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/itsubaki/q/math/eigen"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/quantum/gate"
+)
+
+// PartialTranspose returns the density matrix transposed on the specified qubits.
+func (m *DensityMatrix) PartialTranspose(qb ...int) *DensityMatrix {
+	n := m.NumQubits()
+
+	var mask int
+	for _, q := range qb {
+		mask |= 1 << (n - 1 - q)
+	}
+
+	p, q := m.Dim()
+	rho := matrix.Zero(p, q)
+	for i := range p {
+		for j := range q {
+			ti := (i &^ mask) | (j & mask)
+			tj := (j &^ mask) | (i & mask)
+			rho.Set(ti, tj, m.At(i, j))
+		}
+	}
+
+	return &DensityMatrix{
+		rho: rho,
+	}
+}
+
+// Negativity returns the negativity of the bipartition of the specified qubits and the others.
+// It is the sum of the absolute values of the negative eigenvalues of the partial transpose.
+func (m *DensityMatrix) Negativity(qb []int, tol ...float64) float64 {
+	return (m.traceNorm(qb, tol...) - 1) / 2
+}
+
+// LogNegativity returns the logarithmic negativity of the bipartition of the specified qubits and the others.
+func (m *DensityMatrix) LogNegativity(qb []int, tol ...float64) float64 {
+	return math.Log2(m.traceNorm(qb, tol...))
+}
+
+// Concurrence returns the Wootters concurrence of the two-qubit density matrix.
+// It panics if m is not a two-qubit density matrix.
+func (m *DensityMatrix) Concurrence(tol ...float64) float64 {
+	if m.NumQubits() != 2 {
+		panic(fmt.Sprintf("density: concurrence of %d-qubit density matrix", m.NumQubits()))
+	}
+
+	// the spin-flipped state (Y x Y) rho* (Y x Y).
+	yy := gate.Y(2)
+	flip := matrix.MatMul(yy, m.rho.Conjugate(), yy)
+
+	// the square roots of the eigenvalues of rho * flip are the eigenvalues of sqrt(sqrt(rho) flip sqrt(rho)).
+	s := m.Sqrt(tol...).rho
+	r := matrix.MatMul(s, flip, s)
+	_, d := eigen.Jacobi(r, eigen.Iterations(r), tol...)
+
+	lambda := make([]float64, d.Rows)
+	for i := range d.Rows {
+		lambda[i] = math.Sqrt(max(real(d.At(i, i)), 0))
+	}
+	slices.Sort(lambda)
+
+	return max(0, lambda[3]-lambda[2]-lambda[1]-lambda[0])
+}
+
+// EntanglementOfFormation returns the entanglement of formation of the two-qubit density matrix.
+// It panics if m is not a two-qubit density matrix.
+func (m *DensityMatrix) EntanglementOfFormation(tol ...float64) float64 {
+	c := m.Concurrence(tol...)
+
+	x := (1 + math.Sqrt(1-c*c)) / 2
+	if x >= 1 {
+		return 0
+	}
+
+	return -x*math.Log2(x) - (1-x)*math.Log2(1-x)
+}
+
+// EntanglementEntropy returns the von Neumann entropy of the reduced density matrix of the specified qubits.
+// It quantifies the entanglement of the bipartition for a pure state.
+func (m *DensityMatrix) EntanglementEntropy(qb []int, tol ...float64) float64 {
+	return m.reduce(qb).VonNeumannEntropy(tol...)
+}
+
+// MutualInformation returns the quantum mutual information S(A) + S(B) - S(AB) between the qubits a and b.
+func (m *DensityMatrix) MutualInformation(a, b []int, tol ...float64) float64 {
+	sa := m.reduce(a).VonNeumannEntropy(tol...)
+	sb := m.reduce(b).VonNeumannEntropy(tol...)
+	sab := m.reduce(append(slices.Clone(a), b...)).VonNeumannEntropy(tol...)
+	return sa + sb - sab
+}
+
+// ConditionalEntropy returns the conditional entropy S(AB) - S(B) of the qubits a given the qubits b.
+// It is negative for some entangled states.
+func (m *DensityMatrix) ConditionalEntropy(a, b []int, tol ...float64) float64 {
+	sb := m.reduce(b).VonNeumannEntropy(tol...)
+	sab := m.reduce(append(slices.Clone(a), b...)).VonNeumannEntropy(tol...)
+	return sab - sb
+}
+
+// traceNorm returns the trace norm of the partial transpose on the specified qubits.
+func (m *DensityMatrix) traceNorm(qb []int, tol ...float64) float64 {
+	pt := m.PartialTranspose(qb...).rho
+	_, d := eigen.Jacobi(pt, eigen.Iterations(pt), tol...)
+
+	var sum float64
+	for i := range d.Rows {
+		sum += math.Abs(real(d.At(i, i)))
+	}
+
+	return sum
+}
+
+// reduce returns the reduced density matrix of the specified qubits.
+func (m *DensityMatrix) reduce(qb []int) *DensityMatrix {
+	var out []int
+	for i := range m.NumQubits() {
+		if slices.Contains(qb, i) {
+			continue
+		}
+
+		out = append(out, i)
+	}
+
+	if len(out) == 0 {
+		return m
+	}
+
+	return m.PartialTrace(out...)
+}
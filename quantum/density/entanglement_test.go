@@ -0,0 +1,190 @@
+package density_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/density"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleDensityMatrix_Concurrence() {
+	bell := qubit.Zeros(2).H(0).CX(0, 1)
+	rho := density.New(bell)
+
+	fmt.Printf("%.4f\n", rho.Concurrence())
+	fmt.Printf("%.4f\n", rho.EntanglementOfFormation())
+	fmt.Printf("%.4f\n", rho.Negativity([]int{0}))
+	fmt.Printf("%.4f\n", rho.LogNegativity([]int{0}))
+	fmt.Printf("%.4f\n", rho.EntanglementEntropy([]int{0}))
+	fmt.Printf("%.4f\n", rho.MutualInformation([]int{0}, []int{1}))
+	fmt.Printf("%.4f\n", rho.ConditionalEntropy([]int{0}, []int{1}))
+
+	// Output:
+	// 1.0000
+	// 1.0000
+	// 0.5000
+	// 1.0000
+	// 1.0000
+	// 2.0000
+	// -1.0000
+}
+
+func TestDensityMatrix_PartialTranspose(t *testing.T) {
+	cases := []struct {
+		in *qubit.Qubit
+		qb []int
+	}{
+		{qubit.Zeros(2).H(0).CX(0, 1), []int{0}},
+		{qubit.Zeros(2).H(0).CX(0, 1).S(1), []int{1}},
+		{qubit.Zeros(3).H(0).CX(0, 2).T(2).H(1), []int{0, 2}},
+		{qubit.Zeros(3).H(0).CX(0, 1).S(1).CX(1, 2), []int{0, 1, 2}},
+	}
+
+	for _, c := range cases {
+		rho := density.New(c.in)
+
+		twice := rho.PartialTranspose(c.qb...).PartialTranspose(c.qb...)
+		if !twice.Equal(rho) {
+			t.Errorf("got=%v, want=%v", twice, rho)
+		}
+
+		if len(c.qb) != rho.NumQubits() {
+			continue
+		}
+
+		// the full transpose of a Hermitian matrix is its complex conjugate.
+		pt := rho.PartialTranspose(c.qb...)
+		for i := range 1 << rho.NumQubits() {
+			for j := range 1 << rho.NumQubits() {
+				if pt.At(i, j) != rho.At(j, i) {
+					t.Errorf("(%v, %v): got=%v, want=%v", i, j, pt.At(i, j), rho.At(j, i))
+				}
+			}
+		}
+	}
+}
+
+func TestDensityMatrix_Concurrence(t *testing.T) {
+	bell := qubit.Zeros(2).H(0).CX(0, 1)
+	mixed := qubit.New(vector.New(1, 0, 0, 0))
+
+	werner := func(p float64) *density.DensityMatrix {
+		states := []density.WeightedState{{Probability: p, Qubit: bell}}
+		for i := range 4 {
+			v := make([]complex128, 4)
+			v[i] = 1
+			states = append(states, density.WeightedState{Probability: (1 - p) / 4, Qubit: qubit.New(vector.New(v...))})
+		}
+
+		return density.NewMixed(states)
+	}
+
+	cases := []struct {
+		rho         *density.DensityMatrix
+		concurrence float64
+		negativity  float64
+	}{
+		{density.New(mixed), 0, 0},
+		{density.New(qubit.Zeros(2).H(0).H(1)), 0, 0},
+		{density.New(bell), 1, 0.5},
+		{werner(1), 1, 0.5},
+		{werner(0.8), 0.7, 0.35},
+		{werner(0.5), 0.25, 0.125},
+		{werner(1.0 / 3), 0, 0},
+		{werner(0.2), 0, 0},
+		{density.New(qubit.Zeros(2).RY(math.Pi/3, 0).CX(0, 1)), math.Sin(math.Pi / 3), math.Sin(math.Pi/3) / 2},
+	}
+
+	for _, c := range cases {
+		if got := c.rho.Concurrence(); !epsilon.IsCloseF64(got, c.concurrence, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, c.concurrence)
+		}
+
+		if got := c.rho.Negativity([]int{0}); !epsilon.IsCloseF64(got, c.negativity, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, c.negativity)
+		}
+
+		if got := c.rho.Negativity([]int{1}); !epsilon.IsCloseF64(got, c.negativity, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, c.negativity)
+		}
+	}
+}
+
+func TestDensityMatrix_MutualInformation(t *testing.T) {
+	ghz := qubit.Zeros(3).H(0).CX(0, 1).CX(1, 2)
+
+	cases := []struct {
+		in          *qubit.Qubit
+		a, b        []int
+		entropy     float64
+		mutual      float64
+		conditional float64
+	}{
+		{qubit.Zeros(2), []int{0}, []int{1}, 0, 0, 0},
+		{qubit.Zeros(2).H(0).CX(0, 1), []int{0}, []int{1}, 1, 2, -1},
+		{ghz, []int{0}, []int{1}, 1, 1, 0},
+		{ghz, []int{0}, []int{1, 2}, 1, 2, -1},
+		{ghz, []int{0, 1}, []int{2}, 1, 2, -1},
+	}
+
+	for _, c := range cases {
+		rho := density.New(c.in)
+		if got := rho.EntanglementEntropy(c.a); !epsilon.IsCloseF64(got, c.entropy, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, c.entropy)
+		}
+
+		if got := rho.MutualInformation(c.a, c.b); !epsilon.IsCloseF64(got, c.mutual, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, c.mutual)
+		}
+
+		if got := rho.ConditionalEntropy(c.a, c.b); !epsilon.IsCloseF64(got, c.conditional, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, c.conditional)
+		}
+	}
+}
+
+func TestDensityMatrix_EntanglementEntropy(t *testing.T) {
+	cases := []struct {
+		n    int
+		seed uint64
+		qb   []int
+	}{
+		{8, 1, []int{0, 1, 2, 3}},
+		{8, 2, []int{1, 3, 5}},
+		{10, 3, []int{0, 1, 2, 3, 4}},
+	}
+
+	for _, c := range cases {
+		r := rand.Const(c.seed)
+		qb := qubit.Zeros(c.n)
+		for range 3 {
+			for i := range c.n {
+				qb.U(r()*math.Pi, r()*math.Pi, r()*math.Pi, i)
+			}
+
+			for i := range c.n - 1 {
+				qb.CX(i, i+1)
+			}
+		}
+
+		want := qb.EntanglementEntropy(c.qb...)
+		if got := density.New(qb).EntanglementEntropy(c.qb); !epsilon.IsCloseF64(got, want, 1e-6) {
+			t.Errorf("%v: got=%v, want=%v", c.qb, got, want)
+		}
+	}
+}
+
+func TestDensityMatrix_Concurrence_panic(t *testing.T) {
+	defer func() {
+		if rec := recover(); rec != "density: concurrence of 3-qubit density matrix" {
+			t.Errorf("got=%v", rec)
+		}
+	}()
+
+	density.New(qubit.Zeros(3)).Concurrence()
+}
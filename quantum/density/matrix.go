@@ -135,7 +135,7 @@ func (m *DensityMatrix) FidelitySquared(sigma *DensityMatrix, tol ...float64) fl
 
 // VonNeumannEntropy returns the von Neumann entropy of the density matrix.
 func (m *DensityMatrix) VonNeumannEntropy(tol ...float64) float64 {
-	_, d := eigen.Jacobi(m.rho, 100, tol...)
+	_, d := eigen.Jacobi(m.rho, eigen.Iterations(m.rho), tol...)
 
 	var sum float64
 	for i := range d.Rows {
@@ -204,7 +204,7 @@ func (m *DensityMatrix) RelativeEntropy(sigma *DensityMatrix, tol ...float64) fl
 
 // Sqrt returns the square root of the density matrix.
 func (m *DensityMatrix) Sqrt(tol ...float64) *DensityMatrix {
-	v, d := eigen.Jacobi(m.rho, 100, tol...)
+	v, d := eigen.Jacobi(m.rho, eigen.Iterations(m.rho), tol...)
 	d.Fdiag(func(lambda complex128) complex128 {
 		return cmplx.Pow(lambda, 0.5)
 	})
@@ -243,6 +243,24 @@ func (m *DensityMatrix) Measure(observable *matrix.Matrix, tol ...float64) (floa
 	}
 }
 
+// MeasureAt returns the probability and post-measurement density matrix
+// for a 2^k x 2^k projector on the k qubits at the given indices.
+// The first index corresponds to the most significant bit of the projector.
+// It does not construct the full-size operator.
+func (m *DensityMatrix) MeasureAt(projector *matrix.Matrix, idx []int, tol ...float64) (float64, *DensityMatrix) {
+	rho := m.ApplyAt(projector, idx...).rho
+	p := real(rho.Trace())
+	if epsilon.IsZeroF64(p, tol...) {
+		return 0, &DensityMatrix{
+			rho: matrix.ZeroLike(m.rho),
+		}
+	}
+
+	return p, &DensityMatrix{
+		rho: rho.Mul(1.0 / complex(p, 0)),
+	}
+}
+
 // PartialTrace returns the density matrix obtained by tracing out the specified qubits.
 // The number of qubits to trace out must be less than or equal to n - 1, where n is the number of qubits in the matrix.
 func (m *DensityMatrix) PartialTrace(qb ...int) *DensityMatrix {
@@ -337,6 +355,18 @@ func (m *DensityMatrix) BitPhaseFlip(p float64, qb ...int) *DensityMatrix {
 	}, qb...)
 }
 
+// ThermalRelaxation returns the density matrix after applying a thermal relaxation channel to the specified qubits.
+// It returns an error if the parameters are invalid. See channel.ThermalRelaxation.
+func (m *DensityMatrix) ThermalRelaxation(t1, t2, time, excited float64, qb ...int) (*DensityMatrix, error) {
+	if _, err := channel.ThermalRelaxation(t1, t2, time, excited, 0); err != nil {
+		return nil, err
+	}
+
+	return m.applyChannelFunc(func(q int) channel.ChannelFunc {
+		return number.Must(channel.ThermalRelaxation(t1, t2, time, excited, q))
+	}, qb...), nil
+}
+
 // applyChannelFunc is a helper function that applies a quantum channel to the specified qubits.
 // If no qubits are specified, the channel is applied to all qubits in the density matrix.
 func (m *DensityMatrix) applyChannelFunc(f func(int) channel.ChannelFunc, qb ...int) *DensityMatrix {
@@ -384,6 +414,91 @@ func (m *DensityMatrix) Apply(u *matrix.Matrix) *DensityMatrix {
 	return m.ApplyKraus(u)
 }
 
+// ApplyAt returns the density matrix u * rho * u^dagger for a 2^k x 2^k operator u on the k qubits at the given indices.
+// The first index corresponds to the most significant bit of u.
+// It does not construct the full-size operator.
+func (m *DensityMatrix) ApplyAt(u *matrix.Matrix, idx ...int) *DensityMatrix {
+	n, k := m.NumQubits(), len(idx)
+
+	// offset[a] is the position of the a-th basis state of u in the rows and columns of rho.
+	var mask int
+	offset := make([]int, 1<<k)
+	for j, t := range idx {
+		b := 1 << (n - 1 - t)
+		mask |= b
+
+		for a := range offset {
+			if a&(1<<(k-1-j)) != 0 {
+				offset[a] |= b
+			}
+		}
+	}
+
+	rho := m.rho.Clone()
+	v := make([]complex128, len(offset))
+
+	// rho = u * rho
+	for i := range rho.Rows {
+		if i&mask != 0 {
+			continue
+		}
+
+		for j := range rho.Cols {
+			for a, o := range offset {
+				v[a] = rho.At(i|o, j)
+			}
+
+			for r, o := range offset {
+				var sum complex128
+				for c := range v {
+					sum += u.At(r, c) * v[c]
+				}
+
+				rho.Set(i|o, j, sum)
+			}
+		}
+	}
+
+	// rho = rho * u^dagger
+	for j := range rho.Cols {
+		if j&mask != 0 {
+			continue
+		}
+
+		for i := range rho.Rows {
+			for a, o := range offset {
+				v[a] = rho.At(i, j|o)
+			}
+
+			for r, o := range offset {
+				var sum complex128
+				for c := range v {
+					sum += cmplx.Conj(u.At(r, c)) * v[c]
+				}
+
+				rho.Set(i, j|o, sum)
+			}
+		}
+	}
+
+	return &DensityMatrix{
+		rho: rho,
+	}
+}
+
+// ApplyChannelAt returns the density matrix after applying a quantum channel on k qubits to the qubits at the given indices.
+// The first index corresponds to the most significant bit of the Kraus operators.
+func (m *DensityMatrix) ApplyChannelAt(ch *channel.Channel, idx ...int) *DensityMatrix {
+	rho := matrix.ZeroLike(m.rho)
+	for _, k := range ch.Kraus {
+		rho = rho.Add(m.ApplyAt(k, idx...).rho)
+	}
+
+	return &DensityMatrix{
+		rho: rho,
+	}
+}
+
 // ApplyKraus returns the density matrix after applying a set of Kraus operators.
 func (m *DensityMatrix) ApplyKraus(ops ...*matrix.Matrix) *DensityMatrix {
 	if len(ops) == 0 {
@@ -275,6 +275,26 @@ func ExampleDensityMatrix_PhaseFlip() {
 	// 0.30
 }
 
+func ExampleDensityMatrix_ThermalRelaxation() {
+	// T1=100us, T2=80us, gate time=20us
+	rho := density.New(qubit.One())
+	s, err := rho.ThermalRelaxation(100, 80, 20, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	p, _ := s.Measure(observable.Projector(qubit.One()))
+	fmt.Printf("%.4f\n", p)
+
+	if _, err := rho.ThermalRelaxation(100, 300, 20, 0); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// 0.8187
+	// invalid t2=300: must be less than or equal to 2*t1=200
+}
+
 func ExampleDensityMatrix_VonNeumannEntropy() {
 	rho := density.New(qubit.Zeros(2).Apply(
 		matrix.TensorProduct(gate.H(), gate.I()),
@@ -515,6 +535,88 @@ func TestDensityMatrix_Apply(t *testing.T) {
 		}
 	}
 }
+
+func TestDensityMatrix_ApplyAt(t *testing.T) {
+	cases := []struct {
+		u    *matrix.Matrix
+		idx  []int
+		want *matrix.Matrix
+	}{
+		{gate.H(), []int{1}, gate.TensorProduct(gate.H(), 3, []int{1})},
+		{gate.U(1, 2, 3), []int{2}, gate.TensorProduct(gate.U(1, 2, 3), 3, []int{2})},
+		{gate.CNOT(2, 0, 1), []int{2, 0}, gate.CNOT(3, 2, 0)},
+		{gate.CR(0.5, 2, 0, 1), []int{0, 2}, gate.CR(0.5, 3, 0, 2)},
+		{gate.QFT(3), []int{0, 1, 2}, gate.QFT(3)},
+	}
+
+	rho := density.NewMixed([]density.WeightedState{
+		{Probability: 0.7, Qubit: qubit.From("+0-")},
+		{Probability: 0.3, Qubit: qubit.From("1+0")},
+	})
+
+	for _, c := range cases {
+		got := rho.ApplyAt(c.u, c.idx...)
+		want := rho.Apply(c.want)
+		if !got.Equal(want) {
+			t.Errorf("%v: got=%v, want=%v", c.idx, got, want)
+		}
+	}
+}
+
+func TestDensityMatrix_MeasureAt(t *testing.T) {
+	p0 := matrix.New([]complex128{1, 0}, []complex128{0, 0})
+	p1 := matrix.New([]complex128{0, 0}, []complex128{0, 1})
+
+	cases := []struct {
+		p    *matrix.Matrix
+		idx  []int
+		want *matrix.Matrix
+	}{
+		{p0, []int{0}, gate.TensorProduct(p0, 3, []int{0})},
+		{p1, []int{1}, gate.TensorProduct(p1, 3, []int{1})},
+		{p1, []int{2}, gate.TensorProduct(p1, 3, []int{2})},
+		{observable.Projector(qubit.From("10")), []int{2, 0}, matrix.TensorProduct(p0, gate.I(), p1)},
+	}
+
+	rho := density.NewMixed([]density.WeightedState{
+		{Probability: 0.7, Qubit: qubit.From("+0-")},
+		{Probability: 0.3, Qubit: qubit.From("1+0")},
+	})
+
+	for _, c := range cases {
+		got, gotrho := rho.MeasureAt(c.p, c.idx)
+		want, wantrho := rho.Measure(c.want)
+		if !epsilon.IsCloseF64(got, want) || !gotrho.Equal(wantrho) {
+			t.Errorf("%v: got=%v, %v, want=%v, %v", c.idx, got, gotrho, want, wantrho)
+		}
+	}
+}
+
+func TestDensityMatrix_ApplyChannelAt(t *testing.T) {
+	cases := []struct {
+		ch   *channel.Channel
+		idx  []int
+		want channel.ChannelFunc
+	}{
+		{channel.Depolarizing(0.3, 0)(1), []int{1}, channel.Depolarizing(0.3, 1)},
+		{channel.AmplitudeDamping(0.4, 0)(1), []int{2}, channel.AmplitudeDamping(0.4, 2)},
+		{channel.ComposeFunc(channel.BitFlip(0.1, 0), channel.PhaseDamping(0.2, 1))(2), []int{2, 0}, channel.ComposeFunc(channel.BitFlip(0.1, 2), channel.PhaseDamping(0.2, 0))},
+	}
+
+	rho := density.NewMixed([]density.WeightedState{
+		{Probability: 0.7, Qubit: qubit.From("+0-")},
+		{Probability: 0.3, Qubit: qubit.From("1+0")},
+	})
+
+	for _, c := range cases {
+		got := rho.ApplyChannelAt(c.ch, c.idx...)
+		want := rho.ApplyChannelFunc(c.want)
+		if !got.Equal(want) {
+			t.Errorf("%v: got=%v, want=%v", c.idx, got, want)
+		}
+	}
+}
+
 func TestDensityMatrix_TraceOut(t *testing.T) {
 	type Case struct {
 		qb   []int
@@ -945,6 +1047,43 @@ func TestDensityMatrix_AmplitudeDamping(t *testing.T) {
 	}
 }
 
+func TestDensityMatrix_ThermalRelaxation(t *testing.T) {
+	cases := []struct {
+		t1, t2, time, excited float64
+	}{
+		{100, 80, 20, 0},
+		{100, 200, 20, 0},
+		{100, 30, 50, 0},
+		{100, 80, 20, 0.1},
+		{50, 100, 1e4, 0.3},
+		{100, 80, 0, 0.2},
+	}
+
+	for _, c := range cases {
+		// the excited-state population decays to the equilibrium with t1.
+		one, err := density.New(qubit.One()).ThermalRelaxation(c.t1, c.t2, c.time, c.excited)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p1 := c.excited + (1-c.excited)*math.Exp(-c.time/c.t1)
+		if got := real(one.At(1, 1)); !epsilon.IsCloseF64(got, p1) {
+			t.Errorf("got=%v, want=%v", got, p1)
+		}
+
+		// the coherence decays with t2.
+		plus, err := density.New(qubit.Plus()).ThermalRelaxation(c.t1, c.t2, c.time, c.excited)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		coh := 0.5 * math.Exp(-c.time/c.t2)
+		if got := real(plus.At(0, 1)); !epsilon.IsCloseF64(got, coh) {
+			t.Errorf("got=%v, want=%v", got, coh)
+		}
+	}
+}
+
 func TestDensityMatrix_PhaseDamping(t *testing.T) {
 	cases := []struct {
 		qubit *qubit.Qubit
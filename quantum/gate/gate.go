@@ -1,9 +1,11 @@
 package gate
 
 import (
+	"fmt"
 	"math"
 	"math/cmplx"
 
+	"github.com/itsubaki/q/math/funm"
 	"github.com/itsubaki/q/math/matrix"
 )
 
@@ -42,6 +44,27 @@ func ABC(theta, phi, lambda float64) (float64, *matrix.Matrix, *matrix.Matrix, *
 	return alpha, A, B, C
 }
 
+// Euler returns alpha, theta, phi, lambda such that u = exp(i * alpha) * U(theta, phi, lambda).
+// u must be a 2x2 unitary matrix.
+func Euler(u *matrix.Matrix) (float64, float64, float64, float64) {
+	u00, u01, u10, u11 := u.At(0, 0), u.At(0, 1), u.At(1, 0), u.At(1, 1)
+	theta := 2 * math.Atan2(cmplx.Abs(u10), cmplx.Abs(u00))
+
+	// cos(theta/2) = 0. phi and lambda are determined up to phi - lambda.
+	if cmplx.Abs(u00) < 1e-12 {
+		alpha := cmplx.Phase(u10)
+		return alpha, theta, 0, cmplx.Phase(-1*u01) - alpha
+	}
+
+	// sin(theta/2) = 0. phi and lambda are determined up to phi + lambda.
+	alpha := cmplx.Phase(u00)
+	if cmplx.Abs(u10) < 1e-12 {
+		return alpha, theta, 0, cmplx.Phase(u11) - alpha
+	}
+
+	return alpha, theta, cmplx.Phase(u10) - alpha, cmplx.Phase(-1*u01) - alpha
+}
+
 // I returns an identity gate.
 func I(n ...int) *matrix.Matrix {
 	return matrix.TensorProductN(matrix.New(
@@ -194,7 +217,7 @@ func Controlled(u *matrix.Matrix, n int, c []int, t int) *matrix.Matrix {
 
 			c := (j >> (n - 1 - t)) & 1
 			r := (i >> (n - 1 - t)) & 1
-			g.Set(i, j, u.At(c, r))
+			g.Set(i, j, u.At(r, c))
 		}
 	}
 
@@ -315,6 +338,33 @@ func QFT(n int) *matrix.Matrix {
 	return g
 }
 
+// Pow returns the principal power u^p of the unitary gate u.
+// For example, Pow(X(), 0.5) is the square root of X gate.
+// It returns an error if u is not unitary.
+func Pow(u *matrix.Matrix, p float64) (*matrix.Matrix, error) {
+	if !u.IsUnitary() {
+		return nil, fmt.Errorf("not unitary")
+	}
+
+	return funm.Pow(u, p)
+}
+
+// Generator returns the hermitian matrix h such that u = exp(-i * h) for the unitary gate u.
+// The eigenvalues of h are in [-pi, pi).
+// It returns an error if u is not unitary.
+func Generator(u *matrix.Matrix) (*matrix.Matrix, error) {
+	if !u.IsUnitary() {
+		return nil, fmt.Errorf("not unitary")
+	}
+
+	log, err := funm.Log(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return log.Mul(1i), nil
+}
+
 // TensorProduct returns the tensor product of u at the specified indices over n qubits.
 func TensorProduct(u *matrix.Matrix, n int, idx []int) *matrix.Matrix {
 	target := make(map[int]bool)
@@ -6,6 +6,7 @@ import (
 	"math/cmplx"
 	"testing"
 
+	"github.com/itsubaki/q/math/funm"
 	"github.com/itsubaki/q/math/matrix"
 	"github.com/itsubaki/q/quantum/gate"
 )
@@ -163,6 +164,173 @@ func FuzzABC(f *testing.F) {
 	})
 }
 
+func ExampleEuler() {
+	alpha, theta, phi, lambda := gate.Euler(gate.H())
+	fmt.Printf("%.4f %.4f %.4f %.4f\n", alpha, theta, phi, lambda)
+
+	u := gate.U(theta, phi, lambda).Mul(cmplx.Exp(complex(0, alpha)))
+	fmt.Println(u.Equal(gate.H()))
+
+	// Output:
+	// 0.0000 1.5708 0.0000 3.1416
+	// true
+}
+
+func TestEuler(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.I()},
+		{gate.X()},
+		{gate.Y()},
+		{gate.Z()},
+		{gate.H()},
+		{gate.S()},
+		{gate.T()},
+		{gate.RX(0.3)},
+		{gate.RY(-1.2)},
+		{gate.RZ(2.5)},
+		{gate.U(1.0, 2.0, 3.0)},
+		{gate.U(math.Pi, 0.5, -0.7).Mul(1i)},
+		{gate.SU(0.1, 0.2, 0.3)},
+		{gate.H().Mul(cmplx.Exp(0.4i))},
+	}
+
+	for _, c := range cases {
+		alpha, theta, phi, lambda := gate.Euler(c.in)
+		got := gate.U(theta, phi, lambda).Mul(cmplx.Exp(complex(0, alpha)))
+		if !got.Equal(c.in) {
+			t.Errorf("got=%v, want=%v", got, c.in)
+		}
+	}
+}
+
+func ExamplePow() {
+	sqrtx, err := gate.Pow(gate.X(), 0.5)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	t, err := gate.Pow(gate.S(), 0.5)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(matrix.MatMul(sqrtx, sqrtx).Equal(gate.X()))
+	fmt.Println(t.Equal(gate.T()))
+
+	// Output:
+	// true
+	// true
+}
+
+func ExampleGenerator() {
+	// RZ(theta) = exp(-i * theta/2 * Z)
+	h, err := gate.Generator(gate.RZ(math.Pi / 2))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, row := range h.Seq2() {
+		fmt.Printf("%.4f\n", row)
+	}
+
+	fmt.Println(h.Equal(gate.Z().Mul(math.Pi / 4)))
+
+	// Output:
+	// [(0.7854+0.0000i) (0.0000+0.0000i)]
+	// [(0.0000+0.0000i) (-0.7854+0.0000i)]
+	// true
+}
+
+func TestGenerator(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{gate.I()},
+		{gate.X()},
+		{gate.H()},
+		{gate.T()},
+		{gate.RX(0.3)},
+		{gate.U(1.0, 2.0, 3.0)},
+		{gate.CNOT(2, 0, 1)},
+		{gate.Swap(2, 0, 1)},
+		{gate.QFT(3)},
+	}
+
+	for _, c := range cases {
+		h, err := gate.Generator(c.in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !h.IsHermitian() {
+			t.Errorf("h=%v is not hermitian", h)
+		}
+
+		if got, err := funm.Exp(h.Mul(-1i)); err != nil || !got.Equal(c.in) {
+			t.Errorf("got=%v, want=%v", got, c.in)
+		}
+	}
+}
+
+func TestPow(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+		p  float64
+		n  int
+	}{
+		{gate.QFT(3), 0.5, 2},
+		{gate.QFT(3), 0.25, 4},
+		{matrix.MatMul(gate.CZ(2, 0, 1), gate.H(2)), 0.5, 2},
+		{gate.Swap(2, 0, 1), 1.0 / 3, 3},
+	}
+
+	for _, c := range cases {
+		got, err := gate.Pow(c.in, c.p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !matrix.ApplyN(got, c.n).Equal(c.in, 1e-12) {
+			t.Errorf("got=%v, want=%v", matrix.ApplyN(got, c.n), c.in)
+		}
+
+		h, err := gate.Generator(c.in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, err := funm.Exp(h.Mul(-1i)); err != nil || !got.Equal(c.in, 1e-12) {
+			t.Errorf("got=%v, want=%v", got, c.in)
+		}
+	}
+}
+
+func TestPow_error(t *testing.T) {
+	cases := []struct {
+		in *matrix.Matrix
+	}{
+		{matrix.Zero(2, 2)},
+		{gate.X().Mul(2)},
+		{matrix.New([]complex128{1, 1}, []complex128{0, 1})},
+		{matrix.Zero(2, 3)},
+	}
+
+	for _, c := range cases {
+		if _, err := gate.Pow(c.in, 0.5); err == nil {
+			t.Errorf("%v: err is nil", c.in)
+		}
+
+		if _, err := gate.Generator(c.in); err == nil {
+			t.Errorf("%v: err is nil", c.in)
+		}
+	}
+}
+
 func TestU(t *testing.T) {
 	cases := []struct {
 		in, want *matrix.Matrix
@@ -217,6 +385,13 @@ func TestControlled(t *testing.T) {
 		{gate.Controlled(gate.X(), 3, []int{2, 0}, 1), gate.CCNOT(3, 2, 0, 1)},
 		{gate.Controlled(gate.X(), 3, []int{0, 1}, 2), gate.CCNOT(3, 0, 1, 2)},
 		{gate.Controlled(gate.X(), 3, []int{1, 0}, 2), gate.CCNOT(3, 1, 0, 2)},
+		{gate.Controlled(gate.S(), 2, []int{0}, 1), gate.CS(2, 0, 1)},
+		{gate.Controlled(gate.RY(1.0), 2, []int{0}, 1), matrix.New(
+			[]complex128{1, 0, 0, 0},
+			[]complex128{0, 1, 0, 0},
+			[]complex128{0, 0, gate.RY(1.0).At(0, 0), gate.RY(1.0).At(0, 1)},
+			[]complex128{0, 0, gate.RY(1.0).At(1, 0), gate.RY(1.0).At(1, 1)},
+		)},
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,626 @@
+// Package mps implements a matrix product state simulator.
+package mps
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strings"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+// DefaultCutoff is the default truncation threshold.
+const DefaultCutoff = 1e-14
+
+// MPS is a matrix product state of qubits.
+// The state is kept in the mixed canonical form around the orthogonality center.
+type MPS struct {
+	sites   []*site
+	center  int
+	maxBond int
+	cutoff  float64
+	err     float64
+	rand    func() float64
+}
+
+// site is a tensor of shape (l, 2, r).
+type site struct {
+	l, r int
+	data []complex128
+}
+
+func newSite(l, r int) *site {
+	return &site{
+		l:    l,
+		r:    r,
+		data: make([]complex128, l*2*r),
+	}
+}
+
+func (t *site) at(a, s, b int) complex128 {
+	return t.data[(a*2+s)*t.r+b]
+}
+
+func (t *site) set(a, s, b int, z complex128) {
+	t.data[(a*2+s)*t.r+b] = z
+}
+
+// New returns a matrix product state of n qubits in the zero state.
+func New(n int) *MPS {
+	sites := make([]*site, n)
+	for i := range n {
+		sites[i] = newSite(1, 1)
+		sites[i].set(0, 0, 0, 1)
+	}
+
+	return &MPS{
+		sites:  sites,
+		cutoff: DefaultCutoff,
+		rand:   rand.Float64,
+	}
+}
+
+// SetMaxBond sets the maximum bond dimension.
+// If d is not positive, the bond dimension is not limited.
+func (m *MPS) SetMaxBond(d int) {
+	m.maxBond = d
+}
+
+// SetCutoff sets the truncation threshold.
+// The smallest singular values are discarded while the sum of their squares is at most cutoff.
+func (m *MPS) SetCutoff(cutoff float64) {
+	m.cutoff = cutoff
+}
+
+// SetRand sets the random number generator.
+func (m *MPS) SetRand(rand func() float64) {
+	m.rand = rand
+}
+
+// NumQubits returns the number of qubits.
+func (m *MPS) NumQubits() int {
+	return len(m.sites)
+}
+
+// BondDim returns the bond dimensions between adjacent qubits.
+func (m *MPS) BondDim() []int {
+	out := make([]int, max(len(m.sites)-1, 0))
+	for i := range out {
+		out[i] = m.sites[i].r
+	}
+
+	return out
+}
+
+// TruncationError returns the sum of the discarded weights of all truncations.
+func (m *MPS) TruncationError() float64 {
+	return m.err
+}
+
+// G applies the 2x2 unitary matrix g to the qubit at idx.
+func (m *MPS) G(g *matrix.Matrix, idx int) *MPS {
+	t := m.sites[idx]
+	out := newSite(t.l, t.r)
+	for a := range t.l {
+		for b := range t.r {
+			v0, v1 := t.at(a, 0, b), t.at(a, 1, b)
+			out.set(a, 0, b, g.At(0, 0)*v0+g.At(0, 1)*v1)
+			out.set(a, 1, b, g.At(1, 0)*v0+g.At(1, 1)*v1)
+		}
+	}
+
+	m.sites[idx] = out
+	return m
+}
+
+// U applies U gate.
+func (m *MPS) U(theta, phi, lambda float64, idx int) *MPS {
+	return m.G(gate.U(theta, phi, lambda), idx)
+}
+
+// I applies I gate.
+func (m *MPS) I(idx int) *MPS {
+	return m
+}
+
+// H applies H gate.
+func (m *MPS) H(idx int) *MPS {
+	return m.G(gate.H(), idx)
+}
+
+// X applies X gate.
+func (m *MPS) X(idx int) *MPS {
+	return m.G(gate.X(), idx)
+}
+
+// Y applies Y gate.
+func (m *MPS) Y(idx int) *MPS {
+	return m.G(gate.Y(), idx)
+}
+
+// Z applies Z gate.
+func (m *MPS) Z(idx int) *MPS {
+	return m.G(gate.Z(), idx)
+}
+
+// S applies S gate.
+func (m *MPS) S(idx int) *MPS {
+	return m.G(gate.S(), idx)
+}
+
+// T applies T gate.
+func (m *MPS) T(idx int) *MPS {
+	return m.G(gate.T(), idx)
+}
+
+// R applies R gate with the given angle.
+func (m *MPS) R(theta float64, idx int) *MPS {
+	return m.G(gate.R(theta), idx)
+}
+
+// RX applies RX gate with the given angle.
+func (m *MPS) RX(theta float64, idx int) *MPS {
+	return m.G(gate.RX(theta), idx)
+}
+
+// RY applies RY gate with the given angle.
+func (m *MPS) RY(theta float64, idx int) *MPS {
+	return m.G(gate.RY(theta), idx)
+}
+
+// RZ applies RZ gate with the given angle.
+func (m *MPS) RZ(theta float64, idx int) *MPS {
+	return m.G(gate.RZ(theta), idx)
+}
+
+// C applies the controlled-g gate, where g is a 2x2 unitary matrix.
+func (m *MPS) C(g *matrix.Matrix, control, target int) *MPS {
+	return m.Apply2(gate.C(g, 2, 0, 1), control, target)
+}
+
+// CU applies controlled-U gate.
+func (m *MPS) CU(theta, phi, lambda float64, control, target int) *MPS {
+	return m.C(gate.U(theta, phi, lambda), control, target)
+}
+
+// CH applies controlled-H gate.
+func (m *MPS) CH(control, target int) *MPS {
+	return m.C(gate.H(), control, target)
+}
+
+// CX applies CNOT gate.
+func (m *MPS) CX(control, target int) *MPS {
+	return m.C(gate.X(), control, target)
+}
+
+// CZ applies CZ gate.
+func (m *MPS) CZ(control, target int) *MPS {
+	return m.C(gate.Z(), control, target)
+}
+
+// CR applies controlled-R gate with the given angle.
+func (m *MPS) CR(theta float64, control, target int) *MPS {
+	return m.C(gate.R(theta), control, target)
+}
+
+// Swap swaps the qubits at i and j.
+func (m *MPS) Swap(i, j int) *MPS {
+	if i == j {
+		return m
+	}
+
+	return m.Apply2(gate.Swap(2, 0, 1), i, j)
+}
+
+// Apply2 applies the 4x4 unitary matrix g to the qubits at i and j,
+// where the qubit at i is the most significant bit of g.
+// If the qubits are not adjacent, they are moved next to each other with swap gates.
+func (m *MPS) Apply2(g *matrix.Matrix, i, j int) *MPS {
+	if i > j {
+		swap := gate.Swap(2, 0, 1)
+		g, i, j = matrix.MatMul(swap, g, swap), j, i
+	}
+
+	swap := gate.Swap(2, 0, 1)
+	for k := j; k > i+1; k-- {
+		m.apply2(swap, k-1)
+	}
+
+	m.apply2(g, i)
+
+	for k := i + 1; k < j; k++ {
+		m.apply2(swap, k)
+	}
+
+	return m
+}
+
+// apply2 applies the 4x4 unitary matrix g to the qubits at i and i+1.
+func (m *MPS) apply2(g *matrix.Matrix, i int) {
+	m.moveCenter(i)
+
+	a, b := m.sites[i], m.sites[i+1]
+	l, r := a.l, b.r
+
+	// theta[(x, s1), (s2, y)] = sum_k a[x, s1, k] * b[k, s2, y]
+	theta := matrix.Zero(l*2, 2*r)
+	for x := range l {
+		for s1 := range 2 {
+			for k := range a.r {
+				v := a.at(x, s1, k)
+				if v == 0 {
+					continue
+				}
+
+				for s2 := range 2 {
+					for y := range r {
+						theta.AddAt(x*2+s1, s2*r+y, v*b.at(k, s2, y))
+					}
+				}
+			}
+		}
+	}
+
+	// apply g to the physical indices.
+	out := matrix.Zero(l*2, 2*r)
+	for x := range l {
+		for y := range r {
+			for s := range 4 {
+				var v complex128
+				for t := range 4 {
+					v += g.At(s, t) * theta.At(x*2+t/2, (t%2)*r+y)
+				}
+
+				out.Set(x*2+s/2, (s%2)*r+y, v)
+			}
+		}
+	}
+
+	u, sv, vd := m.truncate(out)
+	k := len(sv)
+
+	left, right := newSite(l, k), newSite(k, r)
+	for x := range l {
+		for s := range 2 {
+			for c := range k {
+				left.set(x, s, c, u.At(x*2+s, c))
+			}
+		}
+	}
+
+	for c := range k {
+		for s := range 2 {
+			for y := range r {
+				right.set(c, s, y, complex(sv[c], 0)*cmplx.Conj(vd.At(s*r+y, c)))
+			}
+		}
+	}
+
+	m.sites[i], m.sites[i+1] = left, right
+	m.center = i + 1
+}
+
+// truncate returns the truncated singular value decomposition of a.
+// The singular values are normalized so that the sum of their squares is one.
+func (m *MPS) truncate(a *matrix.Matrix) (*matrix.Matrix, []float64, *matrix.Matrix) {
+	u, s, v := matrix.SVD(a)
+
+	var total float64
+	for i := range s {
+		total += s[i] * s[i]
+	}
+
+	k := len(s)
+	if m.maxBond > 0 {
+		k = min(k, m.maxBond)
+	}
+
+	var discarded float64
+	for i := k; i < len(s); i++ {
+		discarded += s[i] * s[i] / total
+	}
+
+	for k > 1 && discarded+s[k-1]*s[k-1]/total <= m.cutoff {
+		discarded += s[k-1] * s[k-1] / total
+		k--
+	}
+
+	m.err += discarded
+
+	norm := math.Sqrt(total * (1 - discarded))
+	sv := make([]float64, k)
+	for i := range k {
+		sv[i] = s[i] / norm
+	}
+
+	return u, sv, v
+}
+
+// moveCenter moves the orthogonality center to the qubit at k.
+func (m *MPS) moveCenter(k int) {
+	for m.center < k {
+		c := m.center
+		a, b := m.sites[c], m.sites[c+1]
+
+		// a[(x, s), y] = u * s * v^dagger
+		mat := matrix.Zero(a.l*2, a.r)
+		copy(mat.Data, a.data)
+		u, s, v := matrix.SVD(mat)
+		d := rank(s)
+
+		left := newSite(a.l, d)
+		for i := range a.l * 2 {
+			for j := range d {
+				left.data[i*d+j] = u.At(i, j)
+			}
+		}
+
+		// b = s * v^dagger * b
+		right := newSite(d, b.r)
+		for i := range d {
+			for x := range a.r {
+				w := complex(s[i], 0) * cmplx.Conj(v.At(x, i))
+				for sb := range 2 {
+					for y := range b.r {
+						right.data[(i*2+sb)*b.r+y] += w * b.at(x, sb, y)
+					}
+				}
+			}
+		}
+
+		m.sites[c], m.sites[c+1] = left, right
+		m.center++
+	}
+
+	for m.center > k {
+		c := m.center
+		a, b := m.sites[c-1], m.sites[c]
+
+		// b[x, (s, y)] = u * s * v^dagger
+		mat := matrix.Zero(b.l, 2*b.r)
+		copy(mat.Data, b.data)
+		u, s, v := matrix.SVD(mat)
+		d := rank(s)
+
+		right := newSite(d, b.r)
+		for i := range d {
+			for j := range 2 * b.r {
+				right.data[i*2*b.r+j] = cmplx.Conj(v.At(j, i))
+			}
+		}
+
+		// a = a * u * s
+		left := newSite(a.l, d)
+		for x := range a.l {
+			for sa := range 2 {
+				for y := range b.l {
+					w := a.at(x, sa, y)
+					for j := range d {
+						left.data[(x*2+sa)*d+j] += w * u.At(y, j) * complex(s[j], 0)
+					}
+				}
+			}
+		}
+
+		m.sites[c-1], m.sites[c] = left, right
+		m.center--
+	}
+}
+
+// rank returns the number of non-zero singular values, at least one.
+func rank(s []float64) int {
+	k := 1
+	for k < len(s) && s[k] > 1e-14*s[0] {
+		k++
+	}
+
+	return k
+}
+
+// Amplitude returns the amplitude of the computational basis state such as "0101".
+// It returns an error if binary is not a string of 0 and 1 of length NumQubits.
+func (m *MPS) Amplitude(binary string) (complex128, error) {
+	if len(binary) != len(m.sites) {
+		return 0, fmt.Errorf("invalid length of %q: %d qubits", binary, len(m.sites))
+	}
+
+	v := []complex128{1}
+	for i, c := range binary {
+		if c != '0' && c != '1' {
+			return 0, fmt.Errorf("invalid character %q in %q", c, binary)
+		}
+
+		t := m.sites[i]
+		s := int(c - '0')
+
+		next := make([]complex128, t.r)
+		for a := range t.l {
+			for b := range t.r {
+				next[b] += v[a] * t.at(a, s, b)
+			}
+		}
+
+		v = next
+	}
+
+	return v[0], nil
+}
+
+// Expect returns the expectation value of the Pauli string such as "XZI".
+// The i-th character is the Pauli operator acting on the qubit at i,
+// and the remaining qubits are the identity.
+// It returns an error if pauli is longer than NumQubits or contains a character other than I, X, Y and Z.
+func (m *MPS) Expect(pauli string) (float64, error) {
+	ops := map[rune]*matrix.Matrix{
+		'I': gate.I(),
+		'X': gate.X(),
+		'Y': gate.Y(),
+		'Z': gate.Z(),
+	}
+
+	if len(pauli) > len(m.sites) {
+		return 0, fmt.Errorf("invalid length of %q: %d qubits", pauli, len(m.sites))
+	}
+
+	for _, c := range pauli {
+		if _, ok := ops[c]; !ok {
+			return 0, fmt.Errorf("invalid Pauli operator %q in %q", c, pauli)
+		}
+	}
+
+	// env[a, a'] is the contraction of the bra and the ket of the left qubits.
+	env := matrix.New([]complex128{1})
+	for i, c := range pauli {
+		t, p := m.sites[i], ops[c]
+
+		next := matrix.Zero(t.r, t.r)
+		for a := range t.l {
+			for a2 := range t.l {
+				e := env.At(a, a2)
+				if e == 0 {
+					continue
+				}
+
+				for s := range 2 {
+					for s2 := range 2 {
+						w := e * p.At(s, s2)
+						if w == 0 {
+							continue
+						}
+
+						for b := range t.r {
+							bra := cmplx.Conj(t.at(a, s, b)) * w
+							for b2 := range t.r {
+								next.AddAt(b, b2, bra*t.at(a2, s2, b2))
+							}
+						}
+					}
+				}
+			}
+		}
+
+		env = next
+	}
+
+	// the remaining qubits are contracted with the identity.
+	for i := len(pauli); i < len(m.sites); i++ {
+		t := m.sites[i]
+
+		next := matrix.Zero(t.r, t.r)
+		for a := range t.l {
+			for a2 := range t.l {
+				e := env.At(a, a2)
+				for s := range 2 {
+					for b := range t.r {
+						bra := cmplx.Conj(t.at(a, s, b)) * e
+						for b2 := range t.r {
+							next.AddAt(b, b2, bra*t.at(a2, s, b2))
+						}
+					}
+				}
+			}
+		}
+
+		env = next
+	}
+
+	return real(env.At(0, 0)), nil
+}
+
+// Measure returns the result of measuring the qubit at idx in the computational basis.
+func (m *MPS) Measure(idx int) int {
+	m.moveCenter(idx)
+
+	t := m.sites[idx]
+	var p0 float64
+	for a := range t.l {
+		for b := range t.r {
+			v := t.at(a, 0, b)
+			p0 += real(v * cmplx.Conj(v))
+		}
+	}
+
+	result, p := 0, p0
+	if m.rand() >= p0 {
+		result, p = 1, 1-p0
+	}
+
+	norm := complex(1/math.Sqrt(p), 0)
+	for a := range t.l {
+		for b := range t.r {
+			t.set(a, result, b, t.at(a, result, b)*norm)
+			t.set(a, 1-result, b, 0)
+		}
+	}
+
+	return result
+}
+
+// Sample returns the counts of the computational basis states measured in shots.
+// The state is not changed.
+func (m *MPS) Sample(shots int) qubit.Counts {
+	m.moveCenter(0)
+
+	counts := make(qubit.Counts)
+	for range shots {
+		var sb strings.Builder
+		env := []complex128{1}
+		for _, t := range m.sites {
+			v := [2][]complex128{make([]complex128, t.r), make([]complex128, t.r)}
+			var p [2]float64
+			for s := range 2 {
+				for a := range t.l {
+					for b := range t.r {
+						v[s][b] += env[a] * t.at(a, s, b)
+					}
+				}
+
+				for b := range t.r {
+					p[s] += real(v[s][b] * cmplx.Conj(v[s][b]))
+				}
+			}
+
+			s := 0
+			if m.rand()*(p[0]+p[1]) >= p[0] {
+				s = 1
+			}
+
+			norm := complex(1/math.Sqrt(p[s]), 0)
+			for b := range v[s] {
+				v[s][b] *= norm
+			}
+
+			env = v[s]
+			sb.WriteByte(byte('0' + s))
+		}
+
+		counts[sb.String()]++
+	}
+
+	return counts
+}
+
+// Clone returns a copy of m.
+func (m *MPS) Clone() *MPS {
+	sites := make([]*site, len(m.sites))
+	for i, t := range m.sites {
+		sites[i] = &site{
+			l:    t.l,
+			r:    t.r,
+			data: append([]complex128{}, t.data...),
+		}
+	}
+
+	return &MPS{
+		sites:   sites,
+		center:  m.center,
+		maxBond: m.maxBond,
+		cutoff:  m.cutoff,
+		err:     m.err,
+		rand:    m.rand,
+	}
+}
@@ -0,0 +1,229 @@
+package mps_test
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strings"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/mps"
+	"github.com/itsubaki/q/quantum/observable"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleNew() {
+	m := mps.New(100)
+	m.H(0)
+	for i := range 99 {
+		m.CX(i, i+1)
+	}
+
+	a, _ := m.Amplitude(fmt.Sprintf("%0100d", 0))
+	zz, _ := m.Expect("ZZ")
+	xx, _ := m.Expect(strings.Repeat("X", 100))
+
+	fmt.Printf("%.4f\n", a)
+	fmt.Printf("%.4f\n", zz)
+	fmt.Printf("%.4f\n", xx)
+	fmt.Println(m.BondDim()[:5])
+
+	// Output:
+	// (0.7071+0.0000i)
+	// 1.0000
+	// 1.0000
+	// [2 2 2 2 2]
+}
+
+func ExampleMPS_SetMaxBond() {
+	m := mps.New(3)
+	m.SetMaxBond(1)
+	m.H(0).CX(0, 1).CX(1, 2)
+
+	fmt.Printf("%.4f\n", m.TruncationError())
+	fmt.Println(m.BondDim())
+
+	// Output:
+	// 0.5000
+	// [1 1]
+}
+
+func ExampleMPS_Sample() {
+	m := mps.New(3)
+	m.SetRand(rand.Const())
+	m.H(0).CX(0, 2)
+
+	counts := m.Sample(1000)
+	fmt.Println(len(counts), counts["000"]+counts["101"])
+
+	// Output:
+	// 2 1000
+}
+
+func ExampleMPS_Measure() {
+	m := mps.New(50)
+	m.SetRand(rand.Const())
+	m.H(0).CX(0, 49)
+
+	r := m.Measure(0)
+	fmt.Println(m.Measure(49) == r)
+
+	// Output:
+	// true
+}
+
+func TestMPS(t *testing.T) {
+	r := rand.Const(1)
+	for range 20 {
+		n := 5
+		m, qb := mps.New(n), qubit.Zeros(n)
+		for range 40 {
+			i, j := int(r()*float64(n)), int(r()*float64(n))
+			theta := r() * 2 * math.Pi
+			switch int(r() * 9) {
+			case 0:
+				m.H(i)
+				qb.H(i)
+			case 1:
+				m.RX(theta, i)
+				qb.RX(theta, i)
+			case 2:
+				m.RY(theta, i).T(i)
+				qb.RY(theta, i).T(i)
+			case 3:
+				m.U(theta, 1, 2, i).S(i)
+				qb.U(theta, 1, 2, i).S(i)
+			case 4:
+				if i != j {
+					m.CX(i, j)
+					qb.CX(i, j)
+				}
+			case 5:
+				if i != j {
+					m.CZ(i, j).CH(j, i)
+					qb.CZ(i, j).CH(j, i)
+				}
+			case 6:
+				if i != j {
+					m.CR(theta, i, j).CU(theta, 1, 2, j, i)
+					qb.CR(theta, i, j).CU(theta, 1, 2, j, i)
+				}
+			case 7:
+				m.Swap(i, j).X(i).Y(j)
+				qb.Swap(i, j).X(i).Y(j)
+			case 8:
+				m.RZ(theta, i).R(theta, j).Z(i).I(j)
+				qb.RZ(theta, i).R(theta, j).Z(i).I(j)
+			}
+		}
+
+		for k, want := range qb.Amplitude() {
+			b := fmt.Sprintf("%0*b", n, k)
+			if got, err := m.Amplitude(b); err != nil || !epsilon.IsClose(got, want, 1e-10) {
+				t.Fatalf("%v: got=%v, want=%v", b, got, want)
+			}
+		}
+
+		v := vector.New(qb.Amplitude()...)
+		for _, s := range []string{"ZIIII", "XYZIX", "YY", "IIIIZ", "XXXXX"} {
+			p := s + "IIIII"[len(s):]
+			want := real(v.Apply(observable.Pauli(p)).InnerProduct(v))
+			if got, err := m.Expect(s); err != nil || math.Abs(got-want) > 1e-10 {
+				t.Fatalf("%v: got=%v, want=%v", s, got, want)
+			}
+		}
+
+		if m.TruncationError() > 1e-10 {
+			t.Fatalf("truncation error=%v", m.TruncationError())
+		}
+	}
+}
+
+func TestMPS_Measure(t *testing.T) {
+	cases := []struct {
+		rand float64
+		want []int
+	}{
+		{0.1, []int{0, 0, 1}},
+		{0.9, []int{1, 1, 1}},
+	}
+
+	for _, c := range cases {
+		m := mps.New(3)
+		m.SetRand(func() float64 { return c.rand })
+		m.H(0).CX(0, 1).X(2)
+
+		clone := m.Clone()
+		for i := range 3 {
+			if got := m.Measure(i); got != c.want[i] {
+				t.Errorf("got=%v, want=%v", got, c.want[i])
+			}
+		}
+
+		if a, _ := clone.Amplitude("001"); !epsilon.IsClose(a, complex(1/math.Sqrt2, 0)) {
+			t.Errorf("got=%v", a)
+		}
+
+		if a, _ := m.Amplitude(fmt.Sprintf("%d%d%d", c.want[0], c.want[1], c.want[2])); !epsilon.IsCloseF64(cmplx.Abs(a), 1) {
+			t.Errorf("got=%v", a)
+		}
+	}
+}
+
+func TestMPS_SetCutoff(t *testing.T) {
+	m := mps.New(2)
+	m.SetCutoff(0.2)
+	m.RY(0.4, 0).CX(0, 1)
+
+	// sin^2(0.2) = 0.0395 is discarded.
+	if got := m.TruncationError(); math.Abs(got-math.Pow(math.Sin(0.2), 2)) > 1e-12 {
+		t.Errorf("got=%v", got)
+	}
+
+	if got := m.BondDim(); got[0] != 1 {
+		t.Errorf("got=%v", got)
+	}
+
+	if got, _ := m.Amplitude("00"); !epsilon.IsClose(got, 1) {
+		t.Errorf("got=%v", got)
+	}
+}
+
+func TestMPS_Amplitude_error(t *testing.T) {
+	cases := []struct {
+		binary string
+		want   string
+	}{
+		{"01", `invalid length of "01": 3 qubits`},
+		{"0101", `invalid length of "0101": 3 qubits`},
+		{"012", `invalid character '2' in "012"`},
+	}
+
+	m := mps.New(3)
+	for _, c := range cases {
+		if _, err := m.Amplitude(c.binary); err == nil || err.Error() != c.want {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+	}
+}
+
+func TestMPS_Expect_error(t *testing.T) {
+	cases := []struct {
+		pauli string
+		want  string
+	}{
+		{"ZZZZ", `invalid length of "ZZZZ": 3 qubits`},
+		{"XA", `invalid Pauli operator 'A' in "XA"`},
+		{"z", `invalid Pauli operator 'z' in "z"`},
+	}
+
+	m := mps.New(3)
+	for _, c := range cases {
+		if _, err := m.Expect(c.pauli); err == nil || err.Error() != c.want {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package noise
+
+import (
+	"sort"
+
+	"github.com/itsubaki/q/quantum/channel"
+	"github.com/itsubaki/q/quantum/readout"
+)
+
+// Model is a noise model that describes the channels applied after the gates,
+// the channel applied to the idle qubits and the readout errors.
+type Model struct {
+	rules   []rule
+	idle    channel.ChannelFunc
+	readout *readout.Error
+}
+
+// rule is a channel applied after the gate on the qubits.
+// If qubits is nil, it matches the gate on any qubits.
+type rule struct {
+	gate   string
+	qubits []int
+	fn     channel.ChannelFunc
+}
+
+// New returns a new empty noise model.
+func New() *Model {
+	return &Model{}
+}
+
+// Add adds the channel applied after the gates on any qubits.
+// The gates are named as the operation types such as "H" and "ControlledX".
+// The i-th qubit of fn is the i-th qubit of the gate, where the control qubits come first.
+func (m *Model) Add(fn channel.ChannelFunc, gate ...string) *Model {
+	for _, g := range gate {
+		m.rules = append(m.rules, rule{gate: g, fn: fn})
+	}
+
+	return m
+}
+
+// AddQubits adds the channel applied after the gates on the set of qubits.
+// It takes precedence over the channels added by Add for the same gates.
+func (m *Model) AddQubits(fn channel.ChannelFunc, qb []int, gate ...string) *Model {
+	for _, g := range gate {
+		m.rules = append(m.rules, rule{gate: g, qubits: set(qb), fn: fn})
+	}
+
+	return m
+}
+
+// SetIdle sets the single-qubit channel applied to each qubit that an operation does not act on.
+// It is applied once per operation, not per layer of parallel operations.
+// For example, H(q0, q1) is the two operations H(q0) and H(q1), and the other qubits idle twice.
+func (m *Model) SetIdle(fn channel.ChannelFunc) *Model {
+	m.idle = fn
+	return m
+}
+
+// SetReadout sets the readout error of the measured qubits.
+// The i-th qubit of e is the qubit at i, and the qubits not described by e are read without error.
+func (m *Model) SetReadout(e *readout.Error) *Model {
+	m.readout = e
+	return m
+}
+
+// Channel returns the channel applied after the gate on the qubits.
+// It returns nil if the gate has no noise.
+func (m *Model) Channel(gate string, qb ...int) *channel.Channel {
+	s := set(qb)
+
+	var fn []channel.ChannelFunc
+	var specific bool
+	for _, r := range m.rules {
+		if r.gate != gate {
+			continue
+		}
+
+		switch {
+		case r.qubits == nil && !specific:
+			fn = append(fn, r.fn)
+		case r.qubits != nil && equal(r.qubits, s):
+			if !specific {
+				fn, specific = nil, true
+			}
+
+			fn = append(fn, r.fn)
+		}
+	}
+
+	if len(fn) == 0 {
+		return nil
+	}
+
+	return channel.ComposeFunc(fn...)(len(qb))
+}
+
+// Idle returns the single-qubit channel applied to the idle qubits.
+// It returns nil if the idle qubits have no noise.
+func (m *Model) Idle() *channel.Channel {
+	if m.idle == nil {
+		return nil
+	}
+
+	return m.idle(1)
+}
+
+// Readout returns the readout error of the measured qubits.
+// It returns nil if the measurements have no readout error.
+// The readout error can be used to mitigate the counts of the noisy simulation.
+func (m *Model) Readout() *readout.Error {
+	return m.readout
+}
+
+// Read returns the bits read for the measured bits of the qubits,
+// where bits[i] is the measured bit of the qubit at qb[i].
+// The bits are misread with the readout error.
+func (m *Model) Read(bits, qb []int, rand func() float64) []int {
+	if m.readout == nil {
+		return bits
+	}
+
+	return m.readout.Read(bits, qb, rand)
+}
+
+// set returns the sorted qubits without duplicates.
+func set(qb []int) []int {
+	out := make([]int, 0, len(qb))
+	seen := make(map[int]bool)
+	for _, q := range qb {
+		if seen[q] {
+			continue
+		}
+
+		seen[q] = true
+		out = append(out, q)
+	}
+
+	sort.Ints(out)
+	return out
+}
+
+// equal returns true if a and b are the same.
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,131 @@
+package noise_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/channel"
+	"github.com/itsubaki/q/quantum/noise"
+	"github.com/itsubaki/q/quantum/qubit"
+	"github.com/itsubaki/q/quantum/readout"
+)
+
+func ExampleModel() {
+	m := noise.New().
+		Add(channel.Depolarizing(0.01, 0), "H", "X").
+		AddQubits(channel.Depolarizing(0.1, 0), []int{2}, "H").
+		SetIdle(channel.PhaseDamping(0.001, 0)).
+		SetReadout(readout.New(readout.Assignment(0.02, 0.05)))
+
+	fmt.Println(len(m.Channel("H", 0).Kraus))
+	fmt.Println(m.Channel("H", 2).Kraus[0].Equal(channel.Depolarizing(0.1, 0)(1).Kraus[0]))
+	fmt.Println(m.Channel("CNOT", 0, 1) == nil)
+	fmt.Println(m.Idle().IsTracePreserving())
+
+	// Output:
+	// 4
+	// true
+	// true
+	// true
+}
+
+func TestModel_Channel(t *testing.T) {
+	dep := channel.Depolarizing(0.1, 0)
+	flip := channel.BitFlip(0.2, 1)
+	damp := channel.AmplitudeDamping(0.3, 1)
+
+	m := noise.New().
+		Add(dep, "H").
+		Add(flip, "ControlledX").
+		Add(damp, "ControlledX").
+		AddQubits(dep, []int{1, 0}, "ControlledX")
+
+	cases := []struct {
+		gate string
+		qb   []int
+		want *channel.Channel
+	}{
+		{"H", []int{0}, dep(1)},
+		{"H", []int{3}, dep(1)},
+		{"X", []int{0}, nil},
+		{"ControlledX", []int{1, 2}, channel.ComposeFunc(flip, damp)(2)},
+		{"ControlledX", []int{0, 1}, dep(2)},
+		{"ControlledX", []int{1, 0}, dep(2)},
+	}
+
+	for _, c := range cases {
+		got := m.Channel(c.gate, c.qb...)
+		if c.want == nil {
+			if got != nil {
+				t.Errorf("%v%v: got=%v, want=nil", c.gate, c.qb, got)
+			}
+
+			continue
+		}
+
+		if got == nil || len(got.Kraus) != len(c.want.Kraus) {
+			t.Fatalf("%v%v: got=%v, want=%v", c.gate, c.qb, got, c.want)
+		}
+
+		for i := range c.want.Kraus {
+			if !got.Kraus[i].Equal(c.want.Kraus[i]) {
+				t.Errorf("%v%v: got=%v, want=%v", c.gate, c.qb, got.Kraus[i], c.want.Kraus[i])
+			}
+		}
+	}
+}
+
+func ExampleModel_Readout() {
+	m := noise.New().SetReadout(readout.New(
+		readout.Assignment(0.1, 0.2),
+		readout.Assignment(0.05, 0.1),
+	))
+
+	// the counts of the noisy simulation are mitigated with the readout error of the model.
+	counts := number.Must(m.Readout().Apply(qubit.Counts{"01": 10000}, rand.Const(1)))
+	p, err := m.Readout().Mitigate(counts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("%.1f\n", p["01"])
+
+	// Output:
+	// 1.0
+}
+
+func TestModel_Read(t *testing.T) {
+	m := noise.New().SetReadout(readout.New(
+		readout.Assignment(1, 0),
+		readout.Assignment(0, 1),
+	))
+
+	cases := []struct {
+		bits, qb []int
+		want     []int
+	}{
+		{[]int{0}, []int{0}, []int{1}},
+		{[]int{1}, []int{0}, []int{1}},
+		{[]int{0}, []int{1}, []int{0}},
+		{[]int{1}, []int{1}, []int{0}},
+		{[]int{0, 1}, []int{2, 1}, []int{0, 0}},
+		{[]int{1, 0, 1}, []int{2, 1, 0}, []int{1, 0, 1}},
+	}
+
+	for _, c := range cases {
+		if got := m.Read(c.bits, c.qb, rand.Const(1)); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("bits=%v, qb=%v: got=%v, want=%v", c.bits, c.qb, got, c.want)
+		}
+	}
+
+	if got := noise.New().Read([]int{0, 1}, []int{0, 1}, rand.Const(1)); fmt.Sprint(got) != "[0 1]" {
+		t.Errorf("got=%v", got)
+	}
+
+	if noise.New().Readout() != nil {
+		t.Errorf("readout is not nil")
+	}
+}
@@ -0,0 +1,249 @@
+package observable
+
+import (
+	"fmt"
+	"math/cmplx"
+	"strings"
+
+	"github.com/itsubaki/q/math/eigen"
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+// Term is a Pauli string with a coefficient, such as 0.5 * "XZ".
+type Term struct {
+	Coef  complex128
+	Pauli string
+}
+
+// PauliSum is a weighted sum of Pauli strings.
+type PauliSum []Term
+
+// NumQubits returns the length of the longest Pauli string in p.
+func (p PauliSum) NumQubits() int {
+	var n int
+	for _, t := range p {
+		n = max(n, len(t.Pauli))
+	}
+
+	return n
+}
+
+// Add returns p + q.
+func (p PauliSum) Add(q PauliSum) PauliSum {
+	out := make(PauliSum, 0, len(p)+len(q))
+	out = append(out, p...)
+	return append(out, q...).Simplify()
+}
+
+// Mul returns z * p.
+func (p PauliSum) Mul(z complex128) PauliSum {
+	out := make(PauliSum, len(p))
+	for i, t := range p {
+		out[i] = Term{Coef: z * t.Coef, Pauli: t.Pauli}
+	}
+
+	return out.Simplify()
+}
+
+// Product returns the operator product pq.
+func (p PauliSum) Product(q PauliSum) PauliSum {
+	out := make(PauliSum, 0, len(p)*len(q))
+	for _, a := range p {
+		for _, b := range q {
+			phase, s := Product(a.Pauli, b.Pauli)
+			out = append(out, Term{Coef: a.Coef * b.Coef * phase, Pauli: s})
+		}
+	}
+
+	return out.Simplify()
+}
+
+// Simplify returns p with the terms of the same Pauli string combined and the zero terms removed.
+// The Pauli strings are padded with I to the same length.
+// The terms are in the order of the first occurrence of the Pauli strings.
+func (p PauliSum) Simplify(tol ...float64) PauliSum {
+	n := p.NumQubits()
+
+	var keys []string
+	coef := make(map[string]complex128)
+	for _, t := range p {
+		s := pad(t.Pauli, n)
+		if _, ok := coef[s]; !ok {
+			keys = append(keys, s)
+		}
+
+		coef[s] += t.Coef
+	}
+
+	out := make(PauliSum, 0, len(keys))
+	for _, s := range keys {
+		if epsilon.IsZero(coef[s], tol...) {
+			continue
+		}
+
+		out = append(out, Term{Coef: coef[s], Pauli: s})
+	}
+
+	return out
+}
+
+// Commutes returns true if p and q commute.
+func (p PauliSum) Commutes(q PauliSum, tol ...float64) bool {
+	return len(p.Product(q).Add(q.Product(p).Mul(-1)).Simplify(tol...)) == 0
+}
+
+// IsHermitian returns true if all the coefficients of the simplified p are real.
+func (p PauliSum) IsHermitian(tol ...float64) bool {
+	for _, t := range p.Simplify(tol...) {
+		if !epsilon.IsZeroF64(imag(t.Coef), tol...) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Matrix returns the matrix representation of p.
+func (p PauliSum) Matrix() *matrix.Matrix {
+	n := p.NumQubits()
+
+	out := matrix.Zero(1<<n, 1<<n)
+	for _, t := range p {
+		out = out.Add(Pauli(pad(t.Pauli, n)).Mul(t.Coef))
+	}
+
+	return out
+}
+
+// Expect returns the real part of the expectation value of p for the state qb.
+// It does not construct the matrix of p.
+// It returns an error if a Pauli string is invalid for qb.
+func (p PauliSum) Expect(qb *qubit.Qubit) (float64, error) {
+	var sum float64
+	for _, t := range p {
+		v, err := qb.ExpectPauli(t.Pauli)
+		if err != nil {
+			return 0, err
+		}
+
+		sum += real(t.Coef) * v
+	}
+
+	return sum, nil
+}
+
+// GroundState returns the lowest eigenvalue of p and its eigenvector.
+// p must be Hermitian. The eigenvalues are computed by the Jacobi method with at most iter rotations.
+func (p PauliSum) GroundState(iter int, tol ...float64) (float64, []complex128) {
+	vectors, lambdas := eigen.Jacobi(p.Matrix(), iter, tol...)
+
+	var k int
+	for i := range lambdas.Rows {
+		if real(lambdas.At(i, i)) < real(lambdas.At(k, k)) {
+			k = i
+		}
+	}
+
+	v := make([]complex128, vectors.Rows)
+	for i := range v {
+		v[i] = vectors.At(i, k)
+	}
+
+	return real(lambdas.At(k, k)), v
+}
+
+// Evolution returns the time evolution operator exp(-i * p * t).
+// p must be Hermitian. The eigenvalues are computed by the Jacobi method with at most iter rotations.
+func (p PauliSum) Evolution(t float64, iter int, tol ...float64) *matrix.Matrix {
+	vectors, lambdas := eigen.Jacobi(p.Matrix(), iter, tol...)
+	for i := range lambdas.Rows {
+		lambdas.Set(i, i, cmplx.Exp(complex(0, -real(lambdas.At(i, i))*t)))
+	}
+
+	return matrix.MatMul(vectors, lambdas, vectors.Dagger())
+}
+
+// String returns the string representation of p, such as "0.5*ZZ - 0.25*XI".
+func (p PauliSum) String() string {
+	if len(p) == 0 {
+		return "0"
+	}
+
+	var sb strings.Builder
+	for i, t := range p {
+		c := t.Coef
+		switch {
+		case i > 0 && imag(c) == 0 && real(c) < 0:
+			sb.WriteString(" - ")
+			c = -c
+		case i > 0:
+			sb.WriteString(" + ")
+		}
+
+		if imag(c) == 0 {
+			sb.WriteString(fmt.Sprintf("%g*%s", real(c), t.Pauli))
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("%g*%s", c, t.Pauli))
+	}
+
+	return sb.String()
+}
+
+// Product returns the product of the Pauli strings a and b as a phase and a Pauli string.
+// The shorter string is padded with I.
+func Product(a, b string) (complex128, string) {
+	n := max(len(a), len(b))
+	a, b = pad(a, n), pad(b, n)
+
+	phase := complex(1, 0)
+	out := make([]byte, n)
+	for i := range n {
+		x, y := a[i], b[i]
+		switch {
+		case x == 'I':
+			out[i] = y
+		case y == 'I':
+			out[i] = x
+		case x == y:
+			out[i] = 'I'
+		default:
+			// XY = iZ, YZ = iX, ZX = iY and the reversed products have -i.
+			out[i] = byte(int('X'+'Y'+'Z') - int(x) - int(y))
+			if (x == 'X' && y == 'Y') || (x == 'Y' && y == 'Z') || (x == 'Z' && y == 'X') {
+				phase *= 1i
+				continue
+			}
+
+			phase *= -1i
+		}
+	}
+
+	return phase, string(out)
+}
+
+// Commutes returns true if the Pauli strings a and b commute.
+func Commutes(a, b string) bool {
+	n := max(len(a), len(b))
+	a, b = pad(a, n), pad(b, n)
+
+	var anti int
+	for i := range n {
+		if a[i] != 'I' && b[i] != 'I' && a[i] != b[i] {
+			anti++
+		}
+	}
+
+	return anti%2 == 0
+}
+
+func pad(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+
+	return s + strings.Repeat("I", n-len(s))
+}
@@ -0,0 +1,128 @@
+package observable_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/observable"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExamplePauliSum() {
+	h := observable.PauliSum{
+		{Coef: 1, Pauli: "XX"},
+		{Coef: 1, Pauli: "YY"},
+		{Coef: 1, Pauli: "ZZ"},
+	}
+
+	e, v := h.GroundState(100)
+	fmt.Printf("%.4f\n", e)
+	fmt.Printf("%.4f\n", number.Must(h.Expect(qubit.New(vector.New(v...)))))
+	fmt.Println(h.IsHermitian())
+
+	// Output:
+	// -3.0000
+	// -3.0000
+	// true
+}
+
+func ExamplePauliSum_Product() {
+	a := observable.PauliSum{{Coef: 1, Pauli: "XI"}, {Coef: 1, Pauli: "ZI"}}
+	b := observable.PauliSum{{Coef: 1, Pauli: "YI"}}
+
+	fmt.Println(a.Product(b))
+	fmt.Println(a.Product(a))
+	fmt.Println(a.Add(b).Mul(2))
+	fmt.Println(a.Add(a.Mul(-1)))
+
+	// Output:
+	// (0+1i)*ZI + (0-1i)*XI
+	// 2*II
+	// 2*XI + 2*ZI + 2*YI
+	// 0
+}
+
+func ExampleProduct() {
+	fmt.Println(observable.Product("XYZ", "YYX"))
+	fmt.Println(observable.Commutes("XX", "ZZ"))
+	fmt.Println(observable.Commutes("XI", "ZZ"))
+
+	// Output:
+	// (-1+0i) ZIY
+	// true
+	// false
+}
+
+func TestPauliSum_Product(t *testing.T) {
+	p := observable.PauliSum{{Coef: 0.5, Pauli: "XZ"}, {Coef: -1i, Pauli: "YI"}, {Coef: 2, Pauli: "IZ"}}
+	q := observable.PauliSum{{Coef: 1, Pauli: "ZY"}, {Coef: 0.25 + 1i, Pauli: "XX"}, {Coef: 3, Pauli: "Z"}}
+
+	cases := []struct {
+		got, want *matrix.Matrix
+	}{
+		{p.Product(q).Matrix(), p.Matrix().MatMul(q.Matrix())},
+		{q.Product(p).Matrix(), q.Matrix().MatMul(p.Matrix())},
+		{p.Add(q).Matrix(), p.Matrix().Add(q.Matrix())},
+		{p.Mul(1i).Matrix(), p.Matrix().Mul(1i)},
+	}
+
+	for _, c := range cases {
+		if !c.got.Equal(c.want) {
+			t.Errorf("got=%v, want=%v", c.got, c.want)
+		}
+	}
+}
+
+func TestPauliSum_Commutes(t *testing.T) {
+	cases := []struct {
+		p, q observable.PauliSum
+		want bool
+	}{
+		{observable.PauliSum{{Coef: 1, Pauli: "XX"}}, observable.PauliSum{{Coef: 1, Pauli: "ZZ"}}, true},
+		{observable.PauliSum{{Coef: 1, Pauli: "XI"}}, observable.PauliSum{{Coef: 1, Pauli: "ZZ"}}, false},
+		{observable.PauliSum{{Coef: 1, Pauli: "XX"}, {Coef: 1, Pauli: "YY"}, {Coef: 1, Pauli: "ZZ"}}, observable.PauliSum{{Coef: 1, Pauli: "ZI"}, {Coef: 1, Pauli: "IZ"}}, true},
+		{observable.PauliSum{{Coef: 1, Pauli: "XI"}, {Coef: 1, Pauli: "IX"}}, observable.PauliSum{{Coef: 1, Pauli: "ZZ"}}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.p.Commutes(c.q); got != c.want {
+			t.Errorf("%v, %v: got=%v, want=%v", c.p, c.q, got, c.want)
+		}
+
+		if got := matrix.Commutes(c.p.Matrix(), c.q.Matrix()); got != c.want {
+			t.Errorf("%v, %v: got=%v, want=%v", c.p, c.q, got, c.want)
+		}
+	}
+}
+
+func TestPauliSum_GroundState(t *testing.T) {
+	// transverse field Ising model on 3 qubits with periodic boundary.
+	h := observable.PauliSum{
+		{Coef: -1, Pauli: "ZZI"}, {Coef: -1, Pauli: "IZZ"}, {Coef: -1, Pauli: "ZIZ"},
+		{Coef: -0.5, Pauli: "XII"}, {Coef: -0.5, Pauli: "IXI"}, {Coef: -0.5, Pauli: "IIX"},
+	}
+
+	e, v := h.GroundState(1000)
+
+	// H|v> = e|v>
+	hv := vector.New(v...).Apply(h.Matrix())
+	for i := range v {
+		if !epsilon.IsClose(hv.Data[i], complex(e, 0)*v[i], 1e-8) {
+			t.Fatalf("got=%v, want=%v", hv.Data[i], complex(e, 0)*v[i])
+		}
+	}
+
+	// the energy is lower than the classical ground state.
+	if e > -3 || math.IsNaN(e) {
+		t.Errorf("got=%v", e)
+	}
+
+	if !h.IsHermitian() || (observable.PauliSum{{Coef: 1i, Pauli: "X"}}).IsHermitian() {
+		t.Fail()
+	}
+}
@@ -0,0 +1,117 @@
+package qubit
+
+import (
+	"sort"
+	"strings"
+)
+
+// Counts is the number of times each binary string is measured.
+type Counts map[string]int
+
+// Shots returns the total number of shots.
+func (c Counts) Shots() int {
+	var sum int
+	for _, v := range c {
+		sum += v
+	}
+
+	return sum
+}
+
+// Probability returns the relative frequency of the binary string.
+func (c Counts) Probability(binary string) float64 {
+	shots := c.Shots()
+	if shots == 0 {
+		return 0
+	}
+
+	return float64(c[binary]) / float64(shots)
+}
+
+// Marginal returns the counts of the bits at the given indices of the binary strings.
+func (c Counts) Marginal(idx ...int) Counts {
+	out := make(Counts)
+	for k, v := range c {
+		var sb strings.Builder
+		for _, i := range idx {
+			sb.WriteByte(k[i])
+		}
+
+		out[sb.String()] += v
+	}
+
+	return out
+}
+
+// MostFrequent returns the n most frequent binary strings.
+// Binary strings with the same count are sorted in ascending order.
+// If n < 0, it returns all binary strings.
+func (c Counts) MostFrequent(n int) []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if c[keys[i]] != c[keys[j]] {
+			return c[keys[i]] > c[keys[j]]
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	if n < 0 || n > len(keys) {
+		return keys
+	}
+
+	return keys[:n]
+}
+
+// Sample returns the counts of shots drawn from the probability distribution of q.
+// If indices are given, the binary strings consist of the bits of the qubits at the indices.
+// The state of q is not changed.
+func (q *Qubit) Sample(shots int, idx ...int) Counts {
+	n := q.NumQubits()
+	if len(idx) < 1 {
+		idx = make([]int, n)
+		for i := range n {
+			idx[i] = i
+		}
+	}
+
+	// the cumulative distribution of the marginal probabilities.
+	marginal := make(map[string]float64)
+	for i, p := range q.Probability() {
+		if p == 0 {
+			continue
+		}
+
+		marginal[bits(n, i, idx)] += p
+	}
+
+	keys := make([]string, 0, len(marginal))
+	for k := range marginal {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cdf := make([]float64, len(keys))
+	var sum float64
+	for i, k := range keys {
+		sum += marginal[k]
+		cdf[i] = sum
+	}
+
+	counts := make(Counts)
+	for range shots {
+		r := q.rand() * sum
+		i := sort.SearchFloat64s(cdf, r)
+		if i < len(cdf) && cdf[i] == r {
+			i++
+		}
+
+		counts[keys[min(i, len(keys)-1)]]++
+	}
+
+	return counts
+}
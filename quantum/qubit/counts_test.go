@@ -0,0 +1,93 @@
+package qubit_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleQubit_Sample() {
+	q := qubit.Zeros(3)
+	q.SetRand(rand.Const())
+	q.H(0).CX(0, 1)
+
+	counts := q.Sample(1000)
+	fmt.Println(counts.Shots())
+	fmt.Println(len(counts), counts["000"]+counts["110"])
+	fmt.Println(q.Sample(10, 2))
+
+	// Output:
+	// 1000
+	// 2 1000
+	// map[0:10]
+}
+
+func ExampleCounts_Marginal() {
+	c := qubit.Counts{
+		"000": 10,
+		"011": 20,
+		"101": 30,
+		"111": 40,
+	}
+
+	fmt.Println(c.Marginal(0))
+	fmt.Println(c.Marginal(2, 1))
+	fmt.Println(c.MostFrequent(2))
+	fmt.Println(c.Probability("101"))
+
+	// Output:
+	// map[0:30 1:70]
+	// map[00:10 10:30 11:60]
+	// [111 101]
+	// 0.3
+}
+
+func TestQubit_Sample(t *testing.T) {
+	cases := []struct {
+		rand []float64
+		want qubit.Counts
+	}{
+		{[]float64{0.0, 0.1}, qubit.Counts{"00": 2}},
+		{[]float64{0.25, 0.49}, qubit.Counts{"01": 2}},
+		{[]float64{0.5, 0.99}, qubit.Counts{"10": 1, "11": 1}},
+	}
+
+	for _, c := range cases {
+		var i int
+		q := qubit.Zeros(2).H(0).H(1)
+		q.SetRand(func() float64 {
+			defer func() { i++ }()
+			return c.rand[i]
+		})
+
+		got := q.Sample(len(c.rand))
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("got=%v, want=%v", got, c.want)
+		}
+	}
+}
+
+func TestCounts_MostFrequent(t *testing.T) {
+	c := qubit.Counts{"00": 1, "01": 3, "10": 3}
+	cases := []struct {
+		n    int
+		want []string
+	}{
+		{-1, []string{"01", "10", "00"}},
+		{0, []string{}},
+		{1, []string{"01"}},
+		{5, []string{"01", "10", "00"}},
+	}
+
+	for _, cs := range cases {
+		if got := c.MostFrequent(cs.n); fmt.Sprint(got) != fmt.Sprint(cs.want) {
+			t.Errorf("got=%v, want=%v", got, cs.want)
+		}
+	}
+
+	if (qubit.Counts{}).Probability("0") != 0 {
+		t.Fail()
+	}
+}
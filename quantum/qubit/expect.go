@@ -0,0 +1,122 @@
+package qubit
+
+import (
+	"fmt"
+	"math/cmplx"
+)
+
+// ExpectPauli returns the expectation value <q|P|q> of the Pauli string P such as "XZI".
+// The i-th character of s acts on the qubit at idx[i], or at i if no indices are given.
+// It does not construct the matrix of P.
+// It returns an error if s contains a character other than I, X, Y and Z,
+// the number of indices is not the length of s, or an index is out of range or duplicated.
+func (q *Qubit) ExpectPauli(s string, idx ...int) (float64, error) {
+	n := q.NumQubits()
+	if err := validPauli(s, idx, n); err != nil {
+		return 0, err
+	}
+
+	// P|j> = i^ny * (-1)^|j & zmask| * |j ^ xmask>
+	var xmask, zmask, ny int
+	for i, c := range []byte(s) {
+		k := i
+		if len(idx) > 0 {
+			k = idx[i]
+		}
+
+		m := 1 << (n - 1 - k)
+		switch c {
+		case 'X':
+			xmask |= m
+		case 'Y':
+			xmask, zmask, ny = xmask|m, zmask|m, ny+1
+		case 'Z':
+			zmask |= m
+		}
+	}
+
+	var sum complex128
+	for j, a := range q.state.Data {
+		if a == 0 {
+			continue
+		}
+
+		v := cmplx.Conj(q.state.Data[j^xmask]) * a
+		if parity(j&zmask) == 1 {
+			v = -v
+		}
+
+		sum += v
+	}
+
+	phase := []complex128{1, 1i, -1, -1i}[ny%4]
+	return real(phase * sum), nil
+}
+
+// ExpectPauliSum returns the expectation value of the weighted sum of Pauli strings.
+// The i-th Pauli string s[i] has the coefficient coef[i].
+// It returns an error if the number of coefficients is not the number of Pauli strings,
+// or a Pauli string is invalid as in ExpectPauli.
+func (q *Qubit) ExpectPauliSum(coef []float64, s []string, idx ...int) (float64, error) {
+	if len(coef) != len(s) {
+		return 0, fmt.Errorf("invalid number of coefficients: %d for %d Pauli strings", len(coef), len(s))
+	}
+
+	var sum float64
+	for i := range s {
+		v, err := q.ExpectPauli(s[i], idx...)
+		if err != nil {
+			return 0, err
+		}
+
+		sum += coef[i] * v
+	}
+
+	return sum, nil
+}
+
+// validPauli returns an error if the Pauli string s on the qubits at idx of n qubits is invalid.
+func validPauli(s string, idx []int, n int) error {
+	for _, c := range s {
+		if c != 'I' && c != 'X' && c != 'Y' && c != 'Z' {
+			return fmt.Errorf("invalid Pauli operator %q in %q", c, s)
+		}
+	}
+
+	if len(idx) == 0 {
+		if len(s) > n {
+			return fmt.Errorf("invalid length of %q: %d qubits", s, n)
+		}
+
+		return nil
+	}
+
+	if len(idx) != len(s) {
+		return fmt.Errorf("invalid number of indices: %d for %q", len(idx), s)
+	}
+
+	seen := make(map[int]bool, len(idx))
+	for _, k := range idx {
+		if k < 0 || k >= n {
+			return fmt.Errorf("index %d out of range for %d qubits", k, n)
+		}
+
+		if seen[k] {
+			return fmt.Errorf("duplicate index %d", k)
+		}
+
+		seen[k] = true
+	}
+
+	return nil
+}
+
+// parity returns the parity of the number of ones in the binary representation of v.
+func parity(v int) int {
+	var p int
+	for ; v > 0; v &= v - 1 {
+		p ^= 1
+	}
+
+	return p
+}
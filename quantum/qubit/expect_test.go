@@ -0,0 +1,110 @@
+package qubit_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/observable"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleQubit_ExpectPauli() {
+	q := qubit.Zeros(2)
+	q.H(0).CX(0, 1)
+
+	fmt.Printf("%.4f\n", number.Must(q.ExpectPauli("ZZ")))
+	fmt.Printf("%.4f\n", number.Must(q.ExpectPauli("XX")))
+	fmt.Printf("%.4f\n", number.Must(q.ExpectPauli("YY")))
+	fmt.Printf("%.4f\n", number.Must(q.ExpectPauli("ZI")))
+	fmt.Printf("%.4f\n", number.Must(q.ExpectPauli("X", 1)))
+
+	// Output:
+	// 1.0000
+	// 1.0000
+	// -1.0000
+	// 0.0000
+	// 0.0000
+}
+
+func ExampleQubit_ExpectPauliSum() {
+	q := qubit.Zeros(2)
+	q.H(0).CX(0, 1)
+
+	// H = 0.5 ZZ - 0.25 XX + 0.1 YI
+	fmt.Printf("%.4f\n", number.Must(q.ExpectPauliSum([]float64{0.5, -0.25, 0.1}, []string{"ZZ", "XX", "YI"})))
+
+	// Output:
+	// 0.2500
+}
+
+func TestQubit_ExpectPauli(t *testing.T) {
+	r := rand.Const(1)
+	for range 10 {
+		n := 4
+		q := qubit.Zeros(n)
+		for i := range n {
+			q.U(r()*math.Pi, r()*math.Pi, r()*math.Pi, i)
+		}
+		q.CX(0, 1).CX(2, 3).CZ(1, 2).CH(3, 0)
+
+		v := vector.New(q.Amplitude()...)
+		for _, s := range []string{"IIII", "XYZI", "YYYY", "ZIXZ", "IYIX", "XXXX"} {
+			want := real(v.Apply(observable.Pauli(s)).InnerProduct(v))
+			if got := number.Must(q.ExpectPauli(s)); math.Abs(got-want) > 1e-12 {
+				t.Errorf("%v: got=%v, want=%v", s, got, want)
+			}
+		}
+
+		// the indices select the qubits the characters act on.
+		if got, want := number.Must(q.ExpectPauli("YX", 3, 1)), number.Must(q.ExpectPauli("IXIY")); math.Abs(got-want) > 1e-12 {
+			t.Errorf("got=%v, want=%v", got, want)
+		}
+	}
+}
+
+func TestQubit_ExpectPauli_error(t *testing.T) {
+	cases := []struct {
+		s    string
+		idx  []int
+		want string
+	}{
+		{"A", nil, `invalid Pauli operator 'A' in "A"`},
+		{"Xz", nil, `invalid Pauli operator 'z' in "Xz"`},
+		{"ZZZZ", nil, `invalid length of "ZZZZ": 3 qubits`},
+		{"ZZ", []int{0}, `invalid number of indices: 1 for "ZZ"`},
+		{"Z", []int{0, 1}, `invalid number of indices: 2 for "Z"`},
+		{"Z", []int{3}, "index 3 out of range for 3 qubits"},
+		{"Z", []int{-1}, "index -1 out of range for 3 qubits"},
+		{"ZX", []int{1, 1}, "duplicate index 1"},
+	}
+
+	q := qubit.Zeros(3)
+	for _, c := range cases {
+		if _, err := q.ExpectPauli(c.s, c.idx...); err == nil || err.Error() != c.want {
+			t.Errorf("%v%v: got=%v, want=%v", c.s, c.idx, err, c.want)
+		}
+	}
+}
+
+func TestQubit_ExpectPauliSum_error(t *testing.T) {
+	cases := []struct {
+		coef []float64
+		s    []string
+		want string
+	}{
+		{[]float64{1}, []string{"ZZ", "XX"}, "invalid number of coefficients: 1 for 2 Pauli strings"},
+		{[]float64{1, 2}, []string{"Z"}, "invalid number of coefficients: 2 for 1 Pauli strings"},
+		{[]float64{1, 2}, []string{"Z", "Q"}, `invalid Pauli operator 'Q' in "Q"`},
+	}
+
+	q := qubit.Zeros(2)
+	for _, c := range cases {
+		if _, err := q.ExpectPauliSum(c.coef, c.s); err == nil || err.Error() != c.want {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+	}
+}
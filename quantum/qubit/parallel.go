@@ -0,0 +1,56 @@
+package qubit
+
+import "sync"
+
+// DefaultThreshold is the default minimum number of qubits for the parallel execution.
+const DefaultThreshold = 14
+
+// Workers returns the number of goroutines the gates and the measurement use.
+func (q *Qubit) Workers() int {
+	return q.workers
+}
+
+// SetWorkers sets the number of goroutines the gates and the measurement use.
+// If n < 2, they run serially.
+func (q *Qubit) SetWorkers(n int) {
+	q.workers = n
+}
+
+// Threshold returns the minimum number of qubits for the parallel execution.
+func (q *Qubit) Threshold() int {
+	return q.threshold
+}
+
+// SetThreshold sets the minimum number of qubits for the parallel execution.
+// Below the threshold, the gates and the measurement run serially even if the workers are set.
+func (q *Qubit) SetThreshold(n int) {
+	q.threshold = n
+}
+
+// parallel calls f with the ranges [lo, hi) that partition [0, size).
+// The ranges are processed concurrently if q has enough qubits and workers.
+func (q *Qubit) parallel(size int, f func(lo, hi int)) {
+	w := min(q.workers, size)
+	if w < 2 || q.n < q.threshold {
+		f(0, size)
+		return
+	}
+
+	var wg sync.WaitGroup
+	chunk := (size + w - 1) / w
+	for lo := 0; lo < size; lo += chunk {
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			f(lo, hi)
+		}(lo, min(lo+chunk, size))
+	}
+
+	wg.Wait()
+}
+
+// pair returns the p-th pair of indices that differ only in the bit of stride.
+func pair(p, stride int) (int, int) {
+	i := (p&^(stride-1))<<1 | p&(stride-1)
+	return i, i + stride
+}
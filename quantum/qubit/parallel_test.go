@@ -0,0 +1,121 @@
+package qubit_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/gate"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func benchmark(b *testing.B, workers int, f func(qb *qubit.Qubit)) {
+	qb := qubit.Zeros(20)
+	qb.SetWorkers(workers)
+
+	b.ResetTimer()
+	for range b.N {
+		f(qb)
+	}
+}
+
+func BenchmarkH(b *testing.B) {
+	for _, w := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", w), func(b *testing.B) {
+			benchmark(b, w, func(qb *qubit.Qubit) { qb.H(10) })
+		})
+	}
+}
+
+func BenchmarkControlledX(b *testing.B) {
+	for _, w := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", w), func(b *testing.B) {
+			benchmark(b, w, func(qb *qubit.Qubit) { qb.ControlledX([]int{0, 5}, 10) })
+		})
+	}
+}
+
+func BenchmarkMeasure(b *testing.B) {
+	for _, w := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", w), func(b *testing.B) {
+			benchmark(b, w, func(qb *qubit.Qubit) { qb.H(10).Measure(10) })
+		})
+	}
+}
+
+func ExampleQubit_SetWorkers() {
+	qb := qubit.Zeros(2)
+	qb.SetWorkers(4)
+	qb.SetThreshold(0)
+
+	qb.H(0).CX(0, 1)
+
+	for _, s := range qb.State() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [00] ( 0.7071 0.0000i): 0.5000
+	// [11] ( 0.7071 0.0000i): 0.5000
+}
+
+func TestQubit_SetWorkers(t *testing.T) {
+	cases := []struct {
+		workers   int
+		threshold int
+	}{
+		{0, 0},
+		{2, 0},
+		{3, 0},
+		{4, 6},
+		{8, 0},
+		{1 << 10, 0},
+	}
+
+	circuit := func(qb *qubit.Qubit) []int {
+		r := rand.Const(1)
+		for i := range 6 {
+			qb.U(r()*math.Pi, r()*math.Pi, r()*math.Pi, i)
+		}
+
+		qb.G(gate.RY(0.3), 2)
+		qb.H(0).X(1).Y(2).Z(3).R(0.4, 4).S(5).T(0)
+		qb.RX(0.5, 1).RY(0.6, 2).RZ(0.7, 3)
+		qb.Controlled(gate.U(0.1, 0.2, 0.3), []int{0, 1}, 2)
+		qb.ControlledU(0.4, 0.5, 0.6, []int{2}, 3)
+		qb.ControlledH([]int{3}, 4).ControlledX([]int{4, 0}, 5)
+		qb.ControlledZ([]int{5}, 0).ControlledR(0.8, []int{1}, 3)
+		qb.Swap(0, 5).ApplyAt(gate.QFT(3), 4, 1, 2)
+
+		qb.SetRand(rand.Const(2))
+		m := make([]int, 3)
+		for i := range m {
+			if qb.Measure(2 * i).IsOne() {
+				m[i] = 1
+			}
+		}
+
+		return m
+	}
+
+	want := qubit.Zeros(6)
+	wm := circuit(want)
+
+	for _, c := range cases {
+		got := qubit.Zeros(6)
+		got.SetWorkers(c.workers)
+		got.SetThreshold(c.threshold)
+
+		gm := circuit(got)
+		if !got.Equal(want) {
+			t.Errorf("%v: got=%v, want=%v", c, got, want)
+		}
+
+		for i := range gm {
+			if gm[i] != wm[i] {
+				t.Errorf("%v: got=%v, want=%v", c, gm, wm)
+			}
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package qubit
 
 import (
+	"fmt"
 	"math"
 	"math/cmplx"
 	"strings"
+	"sync"
 
 	"github.com/itsubaki/q/math/epsilon"
 	"github.com/itsubaki/q/math/matrix"
@@ -14,17 +16,20 @@ import (
 
 // Qubit is a qubit.
 type Qubit struct {
-	n     int
-	state *vector.Vector
-	rand  func() float64 // Random number generator
+	n         int
+	state     *vector.Vector
+	rand      func() float64 // Random number generator
+	workers   int            // the number of goroutines for the parallel execution
+	threshold int            // the minimum number of qubits for the parallel execution
 }
 
 // New returns a new qubit.
 func New(v *vector.Vector) *Qubit {
 	q := &Qubit{
-		n:     number.Log2(len(v.Data)),
-		state: v,
-		rand:  rand.Float64,
+		n:         number.Log2(len(v.Data)),
+		state:     v,
+		rand:      rand.Float64,
+		threshold: DefaultThreshold,
 	}
 
 	q.Normalize()
@@ -193,13 +198,103 @@ func (q *Qubit) G(g *matrix.Matrix, idx int) {
 	g0, g1, g2, g3 := g.Data[0], g.Data[1], g.Data[2], g.Data[3]
 
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			a, b := q.state.Data[i+j], q.state.Data[i+j+stride]
-			q.state.Data[i+j] = g0*a + g1*b
-			q.state.Data[i+j+stride] = g2*a + g3*b
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = g0*a + g1*b
+			q.state.Data[j] = g2*a + g3*b
+		}
+	})
+}
+
+// ApplyAt applies a 2^k x 2^k gate to the k qubits at the given indices.
+// The first index corresponds to the most significant bit of the gate.
+// It does not construct the full-size matrix.
+// It panics if g is not 2^k x 2^k, or the indices are out of range or not distinct.
+func (q *Qubit) ApplyAt(g *matrix.Matrix, idx ...int) *Qubit {
+	n, k := q.NumQubits(), len(idx)
+	if g.Rows != 1<<k || g.Cols != 1<<k {
+		panic(fmt.Sprintf("qubit: gate of size %dx%d for %d qubits", g.Rows, g.Cols, k))
+	}
+
+	seen := make(map[int]bool, k)
+	for _, t := range idx {
+		if t < 0 || t >= n {
+			panic(fmt.Sprintf("qubit: index %d out of range for %d qubits", t, n))
+		}
+
+		if seen[t] {
+			panic(fmt.Sprintf("qubit: duplicate index %d", t))
+		}
+
+		seen[t] = true
+	}
+
+	// offset[a] is the position of the a-th basis state of the gate in the state vector.
+	var mask int
+	offset := make([]int, 1<<k)
+	for j, t := range idx {
+		m := 1 << (n - 1 - t)
+		mask |= m
+
+		for a := range offset {
+			if a&(1<<(k-1-j)) != 0 {
+				offset[a] |= m
+			}
+		}
+	}
+
+	q.parallel(q.Dim(), func(lo, hi int) {
+		v := make([]complex128, len(offset))
+		for i := lo; i < hi; i++ {
+			if i&mask != 0 {
+				continue
+			}
+
+			for a, o := range offset {
+				v[a] = q.state.Data[i|o]
+			}
+
+			for r, o := range offset {
+				var sum complex128
+				for c := range v {
+					sum += g.At(r, c) * v[c]
+				}
+
+				q.state.Data[i|o] = sum
+			}
+		}
+	})
+
+	return q
+}
+
+// ApplyKraus applies one of the Kraus operators to the qubits at the given indices and normalizes q.
+// The i-th operator is chosen with the probability ||K_i|psi>||^2, and its index is returned.
+func (q *Qubit) ApplyKraus(kraus []*matrix.Matrix, idx ...int) int {
+	r := q.rand()
+
+	var sum float64
+	var last *vector.Vector
+	var chosen int
+	for i, k := range kraus {
+		next := q.Clone().ApplyAt(k, idx...)
+
+		p := number.Sum(next.Probability())
+		if p == 0 {
+			continue
+		}
+
+		last, chosen = next.state, i
+		if sum += p; r < sum {
+			break
 		}
 	}
+
+	q.state = last
+	q.Normalize()
+	return chosen
 }
 
 // U applies a unitary gate.
@@ -212,13 +307,14 @@ func (q *Qubit) U(theta, phi, lambda float64, idx int) *Qubit {
 	e2 := cmplx.Exp(complex(0, phi+lambda))
 
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			a, b := q.state.Data[i+j], q.state.Data[i+j+stride]
-			q.state.Data[i+j] = cos*a - e1*sin*b
-			q.state.Data[i+j+stride] = e0*sin*a + e2*cos*b
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = cos*a - e1*sin*b
+			q.state.Data[j] = e0*sin*a + e2*cos*b
 		}
-	}
+	})
 
 	return q
 }
@@ -233,13 +329,14 @@ func (q *Qubit) H(idx int) *Qubit {
 	sqrt2 := complex(1/math.Sqrt2, 0) // avoid runtime.complex128div
 
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			a, b := q.state.Data[i+j], q.state.Data[i+j+stride]
-			q.state.Data[i+j] = (a + b) * sqrt2
-			q.state.Data[i+j+stride] = (a - b) * sqrt2
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = (a + b) * sqrt2
+			q.state.Data[j] = (a - b) * sqrt2
 		}
-	}
+	})
 
 	return q
 }
@@ -247,11 +344,12 @@ func (q *Qubit) H(idx int) *Qubit {
 // X applies X gate.
 func (q *Qubit) X(idx int) *Qubit {
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			q.state.Data[i+j], q.state.Data[i+j+stride] = q.state.Data[i+j+stride], q.state.Data[i+j]
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			q.state.Data[i], q.state.Data[j] = q.state.Data[j], q.state.Data[i]
 		}
-	}
+	})
 
 	return q
 }
@@ -259,13 +357,14 @@ func (q *Qubit) X(idx int) *Qubit {
 // Y applies Y gate.
 func (q *Qubit) Y(idx int) *Qubit {
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			a, b := q.state.Data[i+j], q.state.Data[i+j+stride]
-			q.state.Data[i+j] = b * complex(0, -1)
-			q.state.Data[i+j+stride] = a * complex(0, 1)
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = b * complex(0, -1)
+			q.state.Data[j] = a * complex(0, 1)
 		}
-	}
+	})
 
 	return q
 }
@@ -273,11 +372,12 @@ func (q *Qubit) Y(idx int) *Qubit {
 // Z applies Z gate.
 func (q *Qubit) Z(idx int) *Qubit {
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			q.state.Data[i+j+stride] *= -1
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			_, j := pair(p, stride)
+			q.state.Data[j] *= -1
 		}
-	}
+	})
 
 	return q
 }
@@ -287,13 +387,15 @@ func (q *Qubit) R(theta float64, idx int) *Qubit {
 	mask := 1 << (q.NumQubits() - 1 - idx)
 
 	phase := cmplx.Exp(complex(0, theta))
-	for i := range q.Dim() {
-		if (i & mask) == 0 {
-			continue
-		}
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if (i & mask) == 0 {
+				continue
+			}
 
-		q.state.Data[i] *= phase
-	}
+			q.state.Data[i] *= phase
+		}
+	})
 
 	return q
 }
@@ -314,13 +416,14 @@ func (q *Qubit) RX(theta float64, idx int) *Qubit {
 	sin := cmplx.Sin(complex(theta/2, 0))
 
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			a, b := q.state.Data[i+j], q.state.Data[i+j+stride]
-			q.state.Data[i+j] = cos*a - 1i*sin*b
-			q.state.Data[i+j+stride] = -1i*sin*a + cos*b
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = cos*a - 1i*sin*b
+			q.state.Data[j] = -1i*sin*a + cos*b
 		}
-	}
+	})
 
 	return q
 }
@@ -331,13 +434,14 @@ func (q *Qubit) RY(theta float64, idx int) *Qubit {
 	sin := cmplx.Sin(complex(theta/2, 0))
 
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			a, b := q.state.Data[i+j], q.state.Data[i+j+stride]
-			q.state.Data[i+j] = cos*a - 1*sin*b
-			q.state.Data[i+j+stride] = sin*a + cos*b
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = cos*a - 1*sin*b
+			q.state.Data[j] = sin*a + cos*b
 		}
-	}
+	})
 
 	return q
 }
@@ -348,12 +452,13 @@ func (q *Qubit) RZ(theta float64, idx int) *Qubit {
 	e1 := cmplx.Exp(complex(0, theta/2))
 
 	stride := 1 << (q.NumQubits() - 1 - idx)
-	for i := 0; i < q.Dim(); i += 2 * stride {
-		for j := range stride {
-			q.state.Data[i+j] *= e0
-			q.state.Data[i+j+stride] *= e1
+	q.parallel(q.Dim()/2, func(lo, hi int) {
+		for p := lo; p < hi; p++ {
+			i, j := pair(p, stride)
+			q.state.Data[i] *= e0
+			q.state.Data[j] *= e1
 		}
-	}
+	})
 
 	return q
 }
@@ -399,20 +504,22 @@ func (q *Qubit) Controlled(g *matrix.Matrix, control []int, target int) *Qubit {
 	tmask := 1 << (n - 1 - target)
 
 	g0, g1, g2, g3 := g.Data[0], g.Data[1], g.Data[2], g.Data[3]
-	for i := range q.Dim() {
-		if (i & cmask) != cmask {
-			continue
-		}
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if (i & cmask) != cmask {
+				continue
+			}
 
-		j := i ^ tmask
-		if i > j {
-			continue
-		}
+			j := i ^ tmask
+			if i > j {
+				continue
+			}
 
-		a, b := q.state.Data[i], q.state.Data[j]
-		q.state.Data[i] = g0*a + g1*b
-		q.state.Data[j] = g2*a + g3*b
-	}
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = g0*a + g1*b
+			q.state.Data[j] = g2*a + g3*b
+		}
+	})
 
 	return q
 }
@@ -434,20 +541,22 @@ func (q *Qubit) ControlledU(theta, phi, lambda float64, control []int, target in
 	e1 := cmplx.Exp(complex(0, lambda))
 	e2 := cmplx.Exp(complex(0, phi+lambda))
 
-	for i := range q.Dim() {
-		if (i & cmask) != cmask {
-			continue
-		}
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if (i & cmask) != cmask {
+				continue
+			}
 
-		j := i ^ tmask
-		if i > j {
-			continue
-		}
+			j := i ^ tmask
+			if i > j {
+				continue
+			}
 
-		a, b := q.state.Data[i], q.state.Data[j]
-		q.state.Data[i] = cos*a - e1*sin*b
-		q.state.Data[j] = e0*sin*a + e2*cos*b
-	}
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = cos*a - e1*sin*b
+			q.state.Data[j] = e0*sin*a + e2*cos*b
+		}
+	})
 
 	return q
 }
@@ -464,20 +573,22 @@ func (q *Qubit) ControlledH(control []int, target int) *Qubit {
 
 	// iterate over all states
 	sqrt2 := complex(1/math.Sqrt2, 0)
-	for i := range q.Dim() {
-		if (i & cmask) != cmask {
-			continue
-		}
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if (i & cmask) != cmask {
+				continue
+			}
 
-		j := i ^ tmask
-		if i > j {
-			continue
-		}
+			j := i ^ tmask
+			if i > j {
+				continue
+			}
 
-		a, b := q.state.Data[i], q.state.Data[j]
-		q.state.Data[i] = (a + b) * sqrt2
-		q.state.Data[j] = (a - b) * sqrt2
-	}
+			a, b := q.state.Data[i], q.state.Data[j]
+			q.state.Data[i] = (a + b) * sqrt2
+			q.state.Data[j] = (a - b) * sqrt2
+		}
+	})
 
 	return q
 }
@@ -493,19 +604,21 @@ func (q *Qubit) ControlledX(control []int, target int) *Qubit {
 	tmask := 1 << (n - 1 - target)
 
 	// iterate over all states
-	for i := range q.Dim() {
-		if (i & cmask) != cmask {
-			continue
-		}
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if (i & cmask) != cmask {
+				continue
+			}
 
-		j := i ^ tmask
-		if i > j {
-			continue
-		}
+			j := i ^ tmask
+			if i > j {
+				continue
+			}
 
-		// swap
-		q.state.Data[i], q.state.Data[j] = q.state.Data[j], q.state.Data[i]
-	}
+			// swap
+			q.state.Data[i], q.state.Data[j] = q.state.Data[j], q.state.Data[i]
+		}
+	})
 
 	return q
 }
@@ -522,15 +635,17 @@ func (q *Qubit) ControlledZ(control []int, target int) *Qubit {
 	tmask := 1 << (n - 1 - target)
 
 	// iterate over all states
-	for i := range q.Dim() {
-		if (i & cmask) != cmask {
-			continue
-		}
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if (i & cmask) != cmask {
+				continue
+			}
 
-		if (i & tmask) == tmask {
-			q.state.Data[i] *= -1
+			if (i & tmask) == tmask {
+				q.state.Data[i] *= -1
+			}
 		}
-	}
+	})
 
 	return q
 }
@@ -547,15 +662,17 @@ func (q *Qubit) ControlledR(theta float64, control []int, target int) *Qubit {
 
 	// iterate over all states
 	phase := cmplx.Exp(complex(0, theta))
-	for i := range q.Dim() {
-		if (i & cmask) != cmask {
-			continue
-		}
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			if (i & cmask) != cmask {
+				continue
+			}
 
-		if (i & tmask) == tmask {
-			q.state.Data[i] *= phase
+			if (i & tmask) == tmask {
+				q.state.Data[i] *= phase
+			}
 		}
-	}
+	})
 
 	return q
 }
@@ -570,21 +687,23 @@ func (q *Qubit) Swap(i, j int) *Qubit {
 	imask := 1 << (n - 1 - i)
 	jmask := 1 << (n - 1 - j)
 
-	for k := range q.Dim() {
-		ibit := (k & imask) >> (n - 1 - i)
-		jbit := (k & jmask) >> (n - 1 - j)
+	q.parallel(q.Dim(), func(lo, hi int) {
+		for k := lo; k < hi; k++ {
+			ibit := (k & imask) >> (n - 1 - i)
+			jbit := (k & jmask) >> (n - 1 - j)
 
-		if ibit == jbit {
-			continue
-		}
+			if ibit == jbit {
+				continue
+			}
 
-		l := k ^ (imask | jmask)
-		if k > l {
-			continue
-		}
+			l := k ^ (imask | jmask)
+			if k > l {
+				continue
+			}
 
-		q.state.Data[k], q.state.Data[l] = q.state.Data[l], q.state.Data[k]
-	}
+			q.state.Data[k], q.state.Data[l] = q.state.Data[l], q.state.Data[k]
+		}
+	})
 
 	return q
 }
@@ -601,21 +720,31 @@ func (q *Qubit) Measure(idx int) *Qubit {
 	n := q.NumQubits()
 	mask := 1 << (n - 1 - idx)
 
+	var mu sync.Mutex
 	var prob0 float64
-	for i := range q.Dim() {
-		if (i & mask) == 0 {
-			prob0 += math.Pow(cmplx.Abs(q.state.Data[i]), 2)
+	q.parallel(q.Dim(), func(lo, hi int) {
+		var sum float64
+		for i := lo; i < hi; i++ {
+			if (i & mask) == 0 {
+				sum += math.Pow(cmplx.Abs(q.state.Data[i]), 2)
+			}
 		}
-	}
+
+		mu.Lock()
+		defer mu.Unlock()
+		prob0 += sum
+	})
 
 	collapse := func(q *Qubit, result int) {
-		for i := range q.Dim() {
-			if ((i & mask) >> (n - 1 - idx)) == result {
-				continue
-			}
+		q.parallel(q.Dim(), func(lo, hi int) {
+			for i := lo; i < hi; i++ {
+				if ((i & mask) >> (n - 1 - idx)) == result {
+					continue
+				}
 
-			q.state.Data[i] = 0
-		}
+				q.state.Data[i] = 0
+			}
+		})
 
 		q.Normalize()
 	}
@@ -640,9 +769,11 @@ func (q *Qubit) Normalize() *Qubit {
 // Clone returns a copy of q.
 func (q *Qubit) Clone() *Qubit {
 	return &Qubit{
-		n:     q.n,
-		state: q.state.Clone(),
-		rand:  q.rand,
+		n:         q.n,
+		state:     q.state.Clone(),
+		rand:      q.rand,
+		workers:   q.workers,
+		threshold: q.threshold,
 	}
 }
 
@@ -97,6 +97,51 @@ func ExampleQubit_G() {
 	// [11] ( 0.7071 0.0000i): 0.5000
 }
 
+func ExampleQubit_ApplyAt() {
+	// iSWAP
+	g := gate.New(
+		[]complex128{1, 0, 0, 0},
+		[]complex128{0, 0, 1i, 0},
+		[]complex128{0, 1i, 0, 0},
+		[]complex128{0, 0, 0, 1},
+	)
+
+	qb := qubit.Zeros(3)
+	qb.X(0)
+	qb.ApplyAt(g, 0, 2)
+
+	for _, s := range qb.State() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [001] ( 0.0000 1.0000i): 1.0000
+}
+
+func ExampleQubit_ApplyKraus() {
+	// amplitude damping with gamma=1
+	k0 := matrix.New(
+		[]complex128{1, 0},
+		[]complex128{0, 0},
+	)
+	k1 := matrix.New(
+		[]complex128{0, 1},
+		[]complex128{0, 0},
+	)
+
+	qb := qubit.From("11")
+	i := qb.ApplyKraus([]*matrix.Matrix{k0, k1}, 1)
+
+	fmt.Println(i)
+	for _, s := range qb.State() {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// 1
+	// [10] ( 1.0000 0.0000i): 1.0000
+}
+
 func ExampleQubit_U() {
 	qb := qubit.Zeros(2)
 	qb.U(math.Pi/2, 0, 0, 0)
@@ -1011,3 +1056,100 @@ func TestString(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyAt(t *testing.T) {
+	cases := []struct {
+		g    *matrix.Matrix
+		idx  []int
+		want *matrix.Matrix
+	}{
+		{gate.H(), []int{1}, gate.TensorProduct(gate.H(), 3, []int{1})},
+		{gate.CNOT(2, 0, 1), []int{0, 2}, gate.CNOT(3, 0, 2)},
+		{gate.CNOT(2, 0, 1), []int{2, 0}, gate.CNOT(3, 2, 0)},
+		{gate.CZ(2, 0, 1), []int{1, 2}, gate.CZ(3, 1, 2)},
+		{gate.Swap(2, 0, 1), []int{2, 0}, gate.Swap(3, 0, 2)},
+		{gate.CCNOT(3, 0, 1, 2), []int{2, 0, 1}, gate.CCNOT(3, 2, 0, 1)},
+		{gate.QFT(3), []int{0, 1, 2}, gate.QFT(3)},
+	}
+
+	r := rand.Const(1)
+	for _, c := range cases {
+		qb := qubit.Zeros(3)
+		for i := range 3 {
+			qb.U(r()*math.Pi, r()*math.Pi, r()*math.Pi, i)
+		}
+
+		want := qb.Clone().Apply(c.want)
+		if got := qb.ApplyAt(c.g, c.idx...); !got.Equal(want) {
+			t.Errorf("%v: got=%v, want=%v", c.idx, got, want)
+		}
+	}
+}
+
+func TestApplyAt_panic(t *testing.T) {
+	cases := []struct {
+		g    *matrix.Matrix
+		idx  []int
+		want string
+	}{
+		{gate.CNOT(2, 0, 1), []int{0}, "qubit: gate of size 4x4 for 1 qubits"},
+		{gate.H(), []int{0, 1}, "qubit: gate of size 2x2 for 2 qubits"},
+		{matrix.Zero(2, 4), []int{0}, "qubit: gate of size 2x4 for 1 qubits"},
+		{gate.H(), []int{3}, "qubit: index 3 out of range for 3 qubits"},
+		{gate.H(), []int{-1}, "qubit: index -1 out of range for 3 qubits"},
+		{gate.CNOT(2, 0, 1), []int{1, 1}, "qubit: duplicate index 1"},
+	}
+
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if rec := recover(); rec != c.want {
+					t.Errorf("got=%v, want=%v", rec, c.want)
+				}
+			}()
+
+			qubit.Zeros(3).ApplyAt(c.g, c.idx...)
+		}()
+	}
+}
+
+func TestApplyKraus(t *testing.T) {
+	p0 := matrix.New(
+		[]complex128{1, 0},
+		[]complex128{0, 0},
+	)
+	p1 := matrix.New(
+		[]complex128{0, 0},
+		[]complex128{0, 1},
+	)
+	x := gate.X().Mul(complex(math.Sqrt(0.2), 0))
+	i := gate.I().Mul(complex(math.Sqrt(0.8), 0))
+
+	cases := []struct {
+		in    *qubit.Qubit
+		kraus []*matrix.Matrix
+		idx   []int
+		r     float64
+		want  int
+		state *qubit.Qubit
+	}{
+		{qubit.From("+0"), []*matrix.Matrix{p0, p1}, []int{0}, 0.4, 0, qubit.From("00")},
+		{qubit.From("+0"), []*matrix.Matrix{p0, p1}, []int{0}, 0.6, 1, qubit.From("10")},
+		{qubit.From("10"), []*matrix.Matrix{p0, p1}, []int{0}, 0.1, 1, qubit.From("10")},
+		{qubit.From("01"), []*matrix.Matrix{i, x}, []int{1}, 0.7, 0, qubit.From("01")},
+		{qubit.From("01"), []*matrix.Matrix{i, x}, []int{1}, 0.9, 1, qubit.From("00")},
+		{qubit.From("01"), []*matrix.Matrix{gate.CNOT(2, 0, 1)}, []int{1, 0}, 0.5, 0, qubit.From("11")},
+	}
+
+	for _, c := range cases {
+		c.in.SetRand(func() float64 { return c.r })
+
+		if got := c.in.ApplyKraus(c.kraus, c.idx...); got != c.want {
+			t.Errorf("got=%v, want=%v", got, c.want)
+		}
+
+		if !c.in.Equal(c.state) {
+			t.Errorf("got=%v, want=%v", c.in, c.state)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package qubit
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/vector"
+)
+
+// Schmidt returns the Schmidt decomposition |psi> = sum_k s_k |a_k>|b_k> of q
+// across the bipartition of the qubits at the given indices and the other qubits.
+// The first index corresponds to the most significant bit of a_k, and the other qubits are in ascending order in b_k.
+// The coefficients are non-zero and in descending order.
+// It panics if the indices are out of range or not distinct, or either side of the bipartition is empty.
+func (q *Qubit) Schmidt(idx ...int) (s []float64, a, b []*Qubit) {
+	n := q.NumQubits()
+	if len(idx) == 0 || len(idx) >= n {
+		panic(fmt.Sprintf("qubit: bipartition of %d qubits into %d and %d", n, len(idx), n-len(idx)))
+	}
+
+	seen := make(map[int]bool, len(idx))
+	for _, t := range idx {
+		if t < 0 || t >= n {
+			panic(fmt.Sprintf("qubit: index %d out of range for %d qubits", t, n))
+		}
+
+		if seen[t] {
+			panic(fmt.Sprintf("qubit: duplicate index %d", t))
+		}
+
+		seen[t] = true
+	}
+
+	var rest []int
+	for i := range n {
+		if seen[i] {
+			continue
+		}
+
+		rest = append(rest, i)
+	}
+
+	// m[x, y] is the amplitude of the basis state x of the qubits at idx and y of the others.
+	m := matrix.Zero(1<<len(idx), 1<<len(rest))
+	for i, amp := range q.state.Data {
+		m.Set(sub(n, i, idx), sub(n, i, rest), amp)
+	}
+
+	u, sv, v := matrix.SVD(m)
+	for k := range sv {
+		if epsilon.IsZeroF64(sv[k]) {
+			break
+		}
+
+		ak := make([]complex128, u.Rows)
+		for i := range ak {
+			ak[i] = u.At(i, k)
+		}
+
+		bk := make([]complex128, v.Rows)
+		for i := range bk {
+			bk[i] = cmplx.Conj(v.At(i, k))
+		}
+
+		s = append(s, sv[k])
+		a = append(a, New(vector.New(ak...)))
+		b = append(b, New(vector.New(bk...)))
+	}
+
+	return s, a, b
+}
+
+// SchmidtRank returns the number of the non-zero Schmidt coefficients of q
+// across the bipartition of the qubits at the given indices and the other qubits.
+func (q *Qubit) SchmidtRank(idx ...int) int {
+	s, _, _ := q.Schmidt(idx...)
+	return len(s)
+}
+
+// EntanglementEntropy returns the entanglement entropy of q
+// across the bipartition of the qubits at the given indices and the other qubits.
+func (q *Qubit) EntanglementEntropy(idx ...int) float64 {
+	s, _, _ := q.Schmidt(idx...)
+
+	var sum float64
+	for _, v := range s {
+		p := v * v
+		sum -= p * math.Log2(p)
+	}
+
+	return math.Max(sum, 0)
+}
+
+// sub returns the index of the basis state of the qubits at idx in the i-th basis state of n qubits.
+func sub(n, i int, idx []int) int {
+	var out int
+	for _, j := range idx {
+		out = out<<1 | (i>>(n-1-j))&1
+	}
+
+	return out
+}
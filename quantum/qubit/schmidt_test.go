@@ -0,0 +1,135 @@
+package qubit_test
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/density"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+func ExampleQubit_Schmidt() {
+	qb := qubit.Zeros(2).H(0).CX(0, 1)
+
+	s, a, b := qb.Schmidt(0)
+	for k := range s {
+		fmt.Printf("%.4f %v %v\n", s[k], a[k].State(), b[k].State())
+	}
+
+	fmt.Println(qb.SchmidtRank(0))
+	fmt.Printf("%.4f\n", qb.EntanglementEntropy(0))
+
+	// Output:
+	// 0.7071 [[0] ( 1.0000 0.0000i): 1.0000] [[0] ( 1.0000 0.0000i): 1.0000]
+	// 0.7071 [[1] ( 1.0000 0.0000i): 1.0000] [[1] ( 1.0000 0.0000i): 1.0000]
+	// 2
+	// 1.0000
+}
+
+func TestQubit_Schmidt(t *testing.T) {
+	random := func(n int, seed uint64) *qubit.Qubit {
+		r := rand.Const(seed)
+		qb := qubit.Zeros(n)
+		for range 3 {
+			for i := range n {
+				qb.U(r()*math.Pi, r()*math.Pi, r()*math.Pi, i)
+			}
+
+			for i := range n - 1 {
+				qb.CX(i, i+1)
+			}
+		}
+
+		return qb
+	}
+
+	cases := []struct {
+		in   *qubit.Qubit
+		idx  []int
+		rank int
+	}{
+		{qubit.Zeros(3), []int{1}, 1},
+		{qubit.Zeros(3).H(0).CX(0, 2), []int{0}, 2},
+		{qubit.Zeros(3).H(0).CX(0, 2), []int{1}, 1},
+		{qubit.Zeros(3).H(0).CX(0, 1).CX(1, 2), []int{2, 0}, 2},
+		{qubit.Zeros(4).H(0).H(1).CX(0, 2).CX(1, 3), []int{0, 1}, 4},
+		{qubit.Zeros(4).H(0).H(1).CX(0, 2).CX(1, 3), []int{0, 2}, 1},
+		{random(4, 1), []int{3, 1}, 4},
+		{random(5, 2), []int{2}, 2},
+	}
+
+	for _, c := range cases {
+		s, a, b := c.in.Schmidt(c.idx...)
+		if len(s) != c.rank {
+			t.Errorf("got=%v, want=%v", len(s), c.rank)
+		}
+
+		// reconstruct the amplitudes.
+		n := c.in.NumQubits()
+		for i, amp := range c.in.Amplitude() {
+			var x, y int
+			for _, j := range c.idx {
+				x = x<<1 | (i>>(n-1-j))&1
+			}
+
+			for j := range n {
+				if slices.Contains(c.idx, j) {
+					continue
+				}
+
+				y = y<<1 | (i>>(n-1-j))&1
+			}
+
+			var got complex128
+			for k := range s {
+				got += complex(s[k], 0) * a[k].Amplitude()[x] * b[k].Amplitude()[y]
+			}
+
+			if !epsilon.IsClose(got, amp, 1e-8) {
+				t.Errorf("%v: got=%v, want=%v", i, got, amp)
+			}
+		}
+
+		// the entanglement entropy is the entropy of the reduced density matrix.
+		var trace []int
+		for j := range n {
+			if !slices.Contains(c.idx, j) {
+				trace = append(trace, j)
+			}
+		}
+
+		want := density.New(c.in).PartialTrace(trace...).VonNeumannEntropy()
+		if got := c.in.EntanglementEntropy(c.idx...); !epsilon.IsCloseF64(got, want, 1e-6) {
+			t.Errorf("got=%v, want=%v", got, want)
+		}
+	}
+}
+
+func TestQubit_Schmidt_panic(t *testing.T) {
+	cases := []struct {
+		idx  []int
+		want string
+	}{
+		{[]int{}, "qubit: bipartition of 3 qubits into 0 and 3"},
+		{[]int{0, 1, 2}, "qubit: bipartition of 3 qubits into 3 and 0"},
+		{[]int{3}, "qubit: index 3 out of range for 3 qubits"},
+		{[]int{-1}, "qubit: index -1 out of range for 3 qubits"},
+		{[]int{1, 1}, "qubit: duplicate index 1"},
+	}
+
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if rec := recover(); rec != c.want {
+					t.Errorf("got=%v, want=%v", rec, c.want)
+				}
+			}()
+
+			qubit.Zeros(3).Schmidt(c.idx...)
+		}()
+	}
+}
@@ -0,0 +1,189 @@
+package readout
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+	"strings"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+// Calibrate returns the readout error estimated from the calibration circuits of all 2^n basis states.
+// run prepares the basis state of the binary string, measures it shots times and returns the counts.
+// It describes the correlated readout error of the n qubits.
+func Calibrate(n, shots int, run func(binary string, shots int) qubit.Counts) *Error {
+	a := matrix.Zero(1<<n, 1<<n)
+	for j := range 1 << n {
+		counts := run(fmt.Sprintf("%0*b", n, j), shots)
+		for k, v := range counts {
+			a.Set(number.MustParseInt(k), j, complex(float64(v)/float64(shots), 0))
+		}
+	}
+
+	return New(a)
+}
+
+// CalibrateTensored returns the readout error estimated from the calibration circuits of
+// the all-zero and the all-one states, assuming the readout errors of the qubits are independent.
+// run prepares the basis state of the binary string, measures it shots times and returns the counts.
+func CalibrateTensored(n, shots int, run func(binary string, shots int) qubit.Counts) *Error {
+	c0 := run(strings.Repeat("0", n), shots)
+	c1 := run(strings.Repeat("1", n), shots)
+
+	a := make([]*matrix.Matrix, n)
+	for i := range n {
+		p01 := c0.Marginal(i).Probability("1")
+		p10 := c1.Marginal(i).Probability("0")
+		a[i] = Assignment(p01, p10)
+	}
+
+	return New(a...)
+}
+
+// Mitigate returns the quasi-probabilities of the basis states
+// by applying the inverse of the assignment matrix to the relative frequencies of the counts.
+// The quasi-probabilities sum to one but may be negative.
+// It returns an error if an assignment matrix is singular or a binary string of the counts is not of the number of qubits.
+func (e *Error) Mitigate(counts qubit.Counts) (map[string]float64, error) {
+	for k := range counts {
+		if err := e.valid(k); err != nil {
+			return nil, err
+		}
+	}
+
+	inv := make([]*matrix.Matrix, len(e.blocks))
+	for i, a := range e.blocks {
+		m, err := matrix.Inverse(a)
+		if err != nil {
+			return nil, err
+		}
+
+		inv[i] = m
+	}
+
+	p := e.frequency(counts)
+	x := apply(matrix.TensorProduct(inv...), p)
+	return e.quasi(x), nil
+}
+
+// MitigateLeastSquares returns the probabilities of the basis states that minimize
+// the squared error between the probabilities read with the readout error and the relative frequencies of the counts.
+// Unlike Mitigate, the probabilities are non-negative and sum to one.
+func (e *Error) MitigateLeastSquares(counts qubit.Counts, iter int, tol ...float64) map[string]float64 {
+	a := e.Matrix()
+	at := a.Transpose()
+	p := e.frequency(counts)
+
+	// the step size is the inverse of the upper bound of the largest eigenvalue of a^T a.
+	step := 1 / (norm(a) * norm(at))
+
+	eps := 1e-12
+	if len(tol) > 0 {
+		eps = tol[0]
+	}
+
+	x := p
+	for range iter {
+		r := apply(a, x)
+		for i := range r {
+			r[i] -= p[i]
+		}
+
+		g := apply(at, r)
+		next := make([]float64, len(x))
+		for i := range x {
+			next[i] = x[i] - step*g[i]
+		}
+		next = simplex(next)
+
+		var diff float64
+		for i := range x {
+			diff = max(diff, math.Abs(next[i]-x[i]))
+		}
+
+		x = next
+		if diff < eps {
+			break
+		}
+	}
+
+	return e.quasi(x)
+}
+
+// frequency returns the relative frequencies of the basis states of the counts.
+func (e *Error) frequency(counts qubit.Counts) []float64 {
+	p := make([]float64, 1<<e.NumQubits())
+	for k := range counts {
+		p[number.MustParseInt(k)] = counts.Probability(k)
+	}
+
+	return p
+}
+
+// quasi returns the non-zero values of x keyed by the binary strings of the basis states.
+func (e *Error) quasi(x []float64) map[string]float64 {
+	n := e.NumQubits()
+
+	out := make(map[string]float64)
+	for i, v := range x {
+		if v == 0 {
+			continue
+		}
+
+		out[fmt.Sprintf("%0*b", n, i)] = v
+	}
+
+	return out
+}
+
+// apply returns the real part of the product of m and x.
+func apply(m *matrix.Matrix, x []float64) []float64 {
+	out := make([]float64, m.Rows)
+	for i := range m.Rows {
+		for j := range m.Cols {
+			out[i] += real(m.At(i, j)) * x[j]
+		}
+	}
+
+	return out
+}
+
+// norm returns the maximum absolute column sum of m.
+func norm(m *matrix.Matrix) float64 {
+	var out float64
+	for j := range m.Cols {
+		var sum float64
+		for i := range m.Rows {
+			sum += cmplx.Abs(m.At(i, j))
+		}
+
+		out = max(out, sum)
+	}
+
+	return out
+}
+
+// simplex returns the Euclidean projection of x onto the probability simplex.
+func simplex(x []float64) []float64 {
+	u := append([]float64(nil), x...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(u)))
+
+	var sum, theta float64
+	for i, v := range u {
+		sum += v
+		if t := (sum - 1) / float64(i+1); v-t > 0 {
+			theta = t
+		}
+	}
+
+	out := make([]float64, len(x))
+	for i, v := range x {
+		out[i] = max(v-theta, 0)
+	}
+
+	return out
+}
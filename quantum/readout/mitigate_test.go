@@ -0,0 +1,144 @@
+package readout_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/qubit"
+	"github.com/itsubaki/q/quantum/readout"
+)
+
+func ExampleCalibrateTensored() {
+	device := readout.New(
+		readout.Assignment(0.02, 0.08),
+		readout.Assignment(0.05, 0.10),
+	)
+
+	r := rand.Const(1)
+	run := func(binary string, shots int) qubit.Counts {
+		return number.Must(device.Apply(qubit.Counts{binary: shots}, r))
+	}
+
+	e := readout.CalibrateTensored(2, 100000, run)
+	counts := number.Must(device.Apply(qubit.Counts{"00": 50000, "11": 50000}, r))
+
+	p, err := e.Mitigate(counts)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%.2f %.2f\n", counts.Probability("00"), counts.Probability("11"))
+	fmt.Printf("%.2f %.2f\n", p["00"], p["11"])
+
+	// Output:
+	// 0.47 0.41
+	// 0.50 0.50
+}
+
+func TestCalibrate(t *testing.T) {
+	want := matrix.New(
+		[]complex128{0.90, 0.05, 0.05, 0.02},
+		[]complex128{0.04, 0.85, 0.01, 0.08},
+		[]complex128{0.04, 0.02, 0.90, 0.10},
+		[]complex128{0.02, 0.08, 0.04, 0.80},
+	)
+
+	device := readout.New(want)
+	run := func(binary string, shots int) qubit.Counts {
+		return number.Must(device.Apply(qubit.Counts{binary: shots}, rand.Const(1)))
+	}
+
+	got := readout.Calibrate(2, 100000, run).Matrix()
+	if !got.Equal(want, 0.01) {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestError_Mitigate(t *testing.T) {
+	correlated := matrix.New(
+		[]complex128{0.90, 0.05, 0.05, 0.02},
+		[]complex128{0.04, 0.85, 0.01, 0.08},
+		[]complex128{0.04, 0.02, 0.90, 0.10},
+		[]complex128{0.02, 0.08, 0.04, 0.80},
+	)
+
+	cases := []struct {
+		e     *readout.Error
+		ideal []float64
+	}{
+		{readout.New(readout.Assignment(0.1, 0.2)), []float64{0.3, 0.7}},
+		{readout.New(readout.Assignment(0.1, 0.2), readout.Assignment(0.05, 0.3)), []float64{0.5, 0, 0, 0.5}},
+		{readout.New(correlated), []float64{0.1, 0.2, 0.3, 0.4}},
+		{readout.New(readout.Assignment(0.02, 0.1), correlated), []float64{0.5, 0, 0, 0, 0, 0, 0, 0.5}},
+	}
+
+	for _, c := range cases {
+		// the counts of the exact probabilities read with the readout error.
+		n := c.e.NumQubits()
+		counts := make(qubit.Counts)
+		for i, p := range c.e.Probability(c.ideal) {
+			counts[fmt.Sprintf("%0*b", n, i)] = int(math.Round(p * 1e6))
+		}
+
+		quasi, err := c.e.Mitigate(counts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ls := c.e.MitigateLeastSquares(counts, 10000)
+		for i, want := range c.ideal {
+			k := fmt.Sprintf("%0*b", n, i)
+			if math.Abs(quasi[k]-want) > 1e-4 {
+				t.Errorf("%v: got=%v, want=%v", k, quasi[k], want)
+			}
+
+			if math.Abs(ls[k]-want) > 1e-4 {
+				t.Errorf("%v: got=%v, want=%v", k, ls[k], want)
+			}
+		}
+	}
+}
+
+func TestError_MitigateLeastSquares(t *testing.T) {
+	e := readout.New(readout.Assignment(0.1, 0.2), readout.Assignment(0.1, 0.2))
+
+	// the inverse gives negative quasi-probabilities for the counts not read from any state.
+	counts := qubit.Counts{"00": 100}
+	quasi, err := e.Mitigate(counts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var negative bool
+	for _, v := range quasi {
+		negative = negative || v < 0
+	}
+
+	if !negative {
+		t.Errorf("got=%v", quasi)
+	}
+
+	var sum float64
+	for k, v := range e.MitigateLeastSquares(counts, 10000) {
+		if v < 0 {
+			t.Errorf("%v: got=%v", k, v)
+		}
+
+		sum += v
+	}
+
+	if math.Abs(sum-1) > 1e-12 {
+		t.Errorf("got=%v, want=1", sum)
+	}
+}
+
+func TestError_Mitigate_singular(t *testing.T) {
+	e := readout.New(readout.Assignment(0.5, 0.5))
+	if _, err := e.Mitigate(qubit.Counts{"0": 1}); err == nil {
+		t.Errorf("err is nil")
+	}
+}
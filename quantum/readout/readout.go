@@ -0,0 +1,178 @@
+package readout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+// Error is a readout error described by assignment matrices.
+// The (i, j) element of an assignment matrix is the probability of reading the basis state i for the basis state j.
+type Error struct {
+	blocks []*matrix.Matrix
+	n      []int
+}
+
+// Assignment returns the assignment matrix of a qubit.
+// p01 is the probability of reading 1 for 0, and p10 is the probability of reading 0 for 1.
+func Assignment(p01, p10 float64) *matrix.Matrix {
+	return matrix.New(
+		[]complex128{complex(1-p01, 0), complex(p10, 0)},
+		[]complex128{complex(p01, 0), complex(1-p10, 0)},
+	)
+}
+
+// New returns a new readout error of the tensor product of the assignment matrices.
+// Each assignment matrix acts on the next qubits, and a 2^k x 2^k matrix describes
+// the correlated readout error of k qubits.
+func New(a ...*matrix.Matrix) *Error {
+	n := make([]int, len(a))
+	for i := range a {
+		n[i] = number.Log2(a[i].Rows)
+	}
+
+	return &Error{
+		blocks: a,
+		n:      n,
+	}
+}
+
+// NumQubits returns the number of qubits.
+func (e *Error) NumQubits() int {
+	var sum int
+	for _, n := range e.n {
+		sum += n
+	}
+
+	return sum
+}
+
+// Matrix returns the assignment matrix of all qubits.
+func (e *Error) Matrix() *matrix.Matrix {
+	return matrix.TensorProduct(e.blocks...)
+}
+
+// Probability returns the probabilities of reading the basis states
+// for the probabilities p of the basis states.
+func (e *Error) Probability(p []float64) []float64 {
+	v := make([]complex128, len(p))
+	for i := range p {
+		v[i] = complex(p[i], 0)
+	}
+
+	return vector.New(v...).Apply(e.Matrix()).Real()
+}
+
+// Apply returns the counts read with the readout error for the measured counts.
+// Each shot is misread independently for each assignment matrix.
+// It returns an error if a binary string of the counts is not of the number of qubits.
+func (e *Error) Apply(counts qubit.Counts, rand func() float64) (qubit.Counts, error) {
+	out := make(qubit.Counts)
+	for k, v := range counts {
+		if err := e.valid(k); err != nil {
+			return nil, err
+		}
+
+		for range v {
+			out[e.read(k, rand)]++
+		}
+	}
+
+	return out, nil
+}
+
+// Read returns the bits read with the readout error for the measured bits of the qubits,
+// where bits[i] is the measured bit of the qubit at qb[i].
+// The i-th qubit of e is the qubit at i, and the qubits not described by e are read without error.
+// The assignment matrix of a correlated block is marginalized over the qubits that are not measured,
+// assuming their basis states are uniformly distributed.
+func (e *Error) Read(bits, qb []int, rand func() float64) []int {
+	pos := make(map[int]int, len(qb))
+	for i, q := range qb {
+		pos[q] = i
+	}
+
+	out := append([]int(nil), bits...)
+	var offset int
+	for i, a := range e.blocks {
+		var j, mask int
+		for k := range e.n[i] {
+			p, ok := pos[offset+k]
+			if !ok {
+				continue
+			}
+
+			mask |= 1 << (e.n[i] - 1 - k)
+			j |= bits[p] << (e.n[i] - 1 - k)
+		}
+
+		if mask != 0 {
+			row := sample(a, j, mask, rand)
+			for k := range e.n[i] {
+				if p, ok := pos[offset+k]; ok {
+					out[p] = (row >> (e.n[i] - 1 - k)) & 1
+				}
+			}
+		}
+
+		offset += e.n[i]
+	}
+
+	return out
+}
+
+// valid returns an error if binary is not a binary string of the number of qubits.
+func (e *Error) valid(binary string) error {
+	if len(binary) != e.NumQubits() || strings.Trim(binary, "01") != "" {
+		return fmt.Errorf("invalid binary string %q for %d qubits", binary, e.NumQubits())
+	}
+
+	return nil
+}
+
+// read returns the binary string read for the binary string.
+func (e *Error) read(binary string, rand func() float64) string {
+	var sb strings.Builder
+	var offset int
+	for i, a := range e.blocks {
+		j := number.MustParseInt(binary[offset : offset+e.n[i]])
+		offset += e.n[i]
+
+		sb.WriteString(fmt.Sprintf("%0*b", e.n[i], sample(a, j, a.Rows-1, rand)))
+	}
+
+	return sb.String()
+}
+
+// sample returns the row of the basis state read for the basis state j,
+// which is sampled from the j-th column of the assignment matrix a.
+// Only the bits in mask are sampled, and the columns of the other bits are averaged.
+func sample(a *matrix.Matrix, j, mask int, rand func() float64) int {
+	p := make([]float64, a.Rows)
+	var cols int
+	for c := range a.Cols {
+		if c&mask != j&mask {
+			continue
+		}
+
+		cols++
+		for k := range a.Rows {
+			p[k&mask] += real(a.At(k, c))
+		}
+	}
+
+	r := rand() * float64(cols)
+
+	var sum float64
+	for k := range p {
+		if sum += p[k]; r < sum {
+			return k
+		}
+	}
+
+	return mask
+}
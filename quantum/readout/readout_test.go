@@ -0,0 +1,197 @@
+package readout_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q/math/matrix"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/qubit"
+	"github.com/itsubaki/q/quantum/readout"
+)
+
+func ExampleAssignment() {
+	a := readout.Assignment(0.1, 0.2)
+	for _, r := range a.Real() {
+		fmt.Println(r)
+	}
+
+	// Output:
+	// [0.9 0.2]
+	// [0.1 0.8]
+}
+
+func ExampleError_Probability() {
+	e := readout.New(
+		readout.Assignment(0.1, 0.2),
+		readout.Assignment(0, 0.5),
+	)
+
+	fmt.Println(e.NumQubits())
+	fmt.Printf("%.4f\n", e.Probability([]float64{0, 0, 0, 1}))
+
+	// Output:
+	// 2
+	// [0.1000 0.1000 0.4000 0.4000]
+}
+
+func ExampleError_Apply() {
+	e := readout.New(
+		readout.Assignment(0, 1),
+		readout.Assignment(1, 0),
+	)
+
+	counts := number.Must(e.Apply(qubit.Counts{"10": 100}, rand.Const(1)))
+	fmt.Println(counts)
+
+	// Output:
+	// map[01:100]
+}
+
+func ExampleError_Read() {
+	e := readout.New(
+		readout.Assignment(0, 1),
+		readout.Assignment(1, 0),
+	)
+
+	// the qubit at 2 is read without error.
+	fmt.Println(e.Read([]int{1, 0, 1}, []int{1, 0, 2}, rand.Const(1)))
+
+	// Output:
+	// [1 0 1]
+}
+
+func TestError_Apply(t *testing.T) {
+	correlated := matrix.New(
+		[]complex128{0.90, 0.05, 0.05, 0.02},
+		[]complex128{0.04, 0.85, 0.01, 0.08},
+		[]complex128{0.04, 0.02, 0.90, 0.10},
+		[]complex128{0.02, 0.08, 0.04, 0.80},
+	)
+
+	cases := []struct {
+		e      *readout.Error
+		binary string
+	}{
+		{readout.New(readout.Assignment(0.1, 0.2)), "0"},
+		{readout.New(readout.Assignment(0.1, 0.2)), "1"},
+		{readout.New(readout.Assignment(0.1, 0.2), readout.Assignment(0.05, 0.3)), "01"},
+		{readout.New(correlated), "10"},
+		{readout.New(readout.Assignment(0.02, 0.1), correlated), "011"},
+	}
+
+	shots := 20000
+	for _, c := range cases {
+		got := number.Must(c.e.Apply(qubit.Counts{c.binary: shots}, rand.Const(1)))
+
+		p := make([]float64, 1<<c.e.NumQubits())
+		p[number.MustParseInt(c.binary)] = 1
+
+		want := c.e.Probability(p)
+		for i := range want {
+			k := fmt.Sprintf("%0*b", c.e.NumQubits(), i)
+			if math.Abs(got.Probability(k)-want[i]) > 0.01 {
+				t.Errorf("%v: got=%v, want=%v", k, got.Probability(k), want[i])
+			}
+		}
+	}
+}
+
+func TestError_Read(t *testing.T) {
+	// reads 00 as 11, and the others as 00.
+	correlated := matrix.New(
+		[]complex128{0, 1, 1, 1},
+		[]complex128{0, 0, 0, 0},
+		[]complex128{0, 0, 0, 0},
+		[]complex128{1, 0, 0, 0},
+	)
+
+	cases := []struct {
+		e    *readout.Error
+		bits []int
+		qb   []int
+		want []int
+	}{
+		{readout.New(readout.Assignment(1, 0)), []int{0}, []int{0}, []int{1}},
+		{readout.New(readout.Assignment(1, 0)), []int{1}, []int{0}, []int{1}},
+		{readout.New(readout.Assignment(0, 0), readout.Assignment(1, 1)), []int{0, 1}, []int{0, 1}, []int{0, 0}},
+		{readout.New(readout.Assignment(0, 0), readout.Assignment(1, 1)), []int{1, 0}, []int{1, 0}, []int{0, 0}},
+		{readout.New(correlated), []int{0, 0}, []int{0, 1}, []int{1, 1}},
+		{readout.New(correlated), []int{1, 0}, []int{1, 0}, []int{0, 0}},
+		{readout.New(correlated), []int{1}, []int{1}, []int{0}},
+		{readout.New(correlated), []int{1}, []int{0}, []int{0}},
+		{readout.New(readout.Assignment(1, 1), correlated), []int{0, 0}, []int{3, 4}, []int{0, 0}},
+	}
+
+	for _, c := range cases {
+		if got := c.e.Read(c.bits, c.qb, rand.Const(1)); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%v%v: got=%v, want=%v", c.bits, c.qb, got, c.want)
+		}
+	}
+}
+
+func TestError_Read_marginal(t *testing.T) {
+	// reads 00 as 11, and the others as 00.
+	correlated := matrix.New(
+		[]complex128{0, 1, 1, 1},
+		[]complex128{0, 0, 0, 0},
+		[]complex128{0, 0, 0, 0},
+		[]complex128{1, 0, 0, 0},
+	)
+
+	cases := []struct {
+		e    *readout.Error
+		bits []int
+		qb   []int
+		want float64
+	}{
+		// the unmeasured qubit 0 is 0 or 1 with the equal probability.
+		{readout.New(correlated), []int{0}, []int{1}, 0.5},
+		{readout.New(correlated), []int{0}, []int{0}, 0.5},
+		{readout.New(correlated), []int{1}, []int{0}, 0},
+		{readout.New(readout.Assignment(0.2, 0), correlated), []int{0, 0}, []int{0, 2}, 0.7},
+	}
+
+	shots := 20000
+	for _, c := range cases {
+		r := rand.Const(1)
+
+		var ones int
+		for range shots {
+			got := c.e.Read(c.bits, c.qb, r)
+			for _, b := range got {
+				ones += b
+			}
+		}
+
+		// the mean number of ones read.
+		if got := float64(ones) / float64(shots); math.Abs(got-c.want) > 0.02 {
+			t.Errorf("%v%v: got=%v, want=%v", c.bits, c.qb, got, c.want)
+		}
+	}
+}
+
+func TestError_Apply_invalid(t *testing.T) {
+	e := readout.New(readout.Assignment(0.1, 0.2), readout.Assignment(0.05, 0.3))
+
+	cases := []struct {
+		counts qubit.Counts
+		want   string
+	}{
+		{qubit.Counts{"0": 10}, `invalid binary string "0" for 2 qubits`},
+		{qubit.Counts{"010": 10}, `invalid binary string "010" for 2 qubits`},
+		{qubit.Counts{"0a": 10}, `invalid binary string "0a" for 2 qubits`},
+	}
+
+	for _, c := range cases {
+		if _, err := e.Apply(c.counts, rand.Const(1)); err == nil || err.Error() != c.want {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+
+		if _, err := e.Mitigate(c.counts); err == nil || err.Error() != c.want {
+			t.Errorf("got=%v, want=%v", err, c.want)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Package stabilizer implements the stabilizer formalism for Clifford circuits.
+package stabilizer
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/itsubaki/q/math/rand"
+)
+
+// Tableau is a stabilizer tableau of the Aaronson-Gottesman (CHP) algorithm.
+// The rows 0 to n-1 are the destabilizers, the rows n to 2n-1 are the stabilizers,
+// and the row 2n is a scratch row used by deterministic measurements.
+type Tableau struct {
+	n    int
+	x, z [][]uint64 // the bits of the rows packed into words
+	r    []uint8    // the phases of the rows. 1 means -1.
+	rand func() float64
+}
+
+// New returns a tableau of n qubits in the zero state.
+func New(n int) *Tableau {
+	t := &Tableau{
+		x:    [][]uint64{nil},
+		z:    [][]uint64{nil},
+		r:    []uint8{0},
+		rand: rand.Float64,
+	}
+
+	t.Add(n)
+	return t
+}
+
+// Add appends n qubits in the zero state.
+func (t *Tableau) Add(n int) *Tableau {
+	for range n {
+		t.add()
+	}
+
+	return t
+}
+
+func (t *Tableau) add() {
+	a := t.n
+	w := words(a + 1)
+	for i := range t.x {
+		for len(t.x[i]) < w {
+			t.x[i] = append(t.x[i], 0)
+			t.z[i] = append(t.z[i], 0)
+		}
+	}
+
+	destab, stab := make([]uint64, w), make([]uint64, w)
+	destab[a/64] = 1 << (a % 64)
+	stab[a/64] = 1 << (a % 64)
+
+	// insert the destabilizer X_a at a and the stabilizer Z_a at 2a+1.
+	x := append(append(append([][]uint64{}, t.x[:a]...), destab), t.x[a:2*a]...)
+	x = append(append(x, make([]uint64, w)), t.x[2*a])
+	z := append(append(append([][]uint64{}, t.z[:a]...), make([]uint64, w)), t.z[a:2*a]...)
+	z = append(append(z, stab), t.z[2*a])
+	r := append(append(append([]uint8{}, t.r[:a]...), 0), t.r[a:2*a]...)
+	r = append(append(r, 0), t.r[2*a])
+
+	t.x, t.z, t.r = x, z, r
+	t.n++
+}
+
+// SetRand sets the random number generator.
+func (t *Tableau) SetRand(rand func() float64) {
+	t.rand = rand
+}
+
+// Rand returns the random number generator.
+func (t *Tableau) Rand() func() float64 {
+	return t.rand
+}
+
+// NumQubits returns the number of qubits.
+func (t *Tableau) NumQubits() int {
+	return t.n
+}
+
+// H applies the Hadamard gate to the qubit at idx.
+func (t *Tableau) H(idx int) *Tableau {
+	w, m := idx/64, uint64(1)<<(idx%64)
+	for i := range 2 * t.n {
+		x, z := t.x[i][w]&m, t.z[i][w]&m
+		if x != 0 && z != 0 {
+			t.r[i] ^= 1
+		}
+
+		t.x[i][w] ^= x ^ z
+		t.z[i][w] ^= x ^ z
+	}
+
+	return t
+}
+
+// S applies the phase gate to the qubit at idx.
+func (t *Tableau) S(idx int) *Tableau {
+	w, m := idx/64, uint64(1)<<(idx%64)
+	for i := range 2 * t.n {
+		x, z := t.x[i][w]&m, t.z[i][w]&m
+		if x != 0 && z != 0 {
+			t.r[i] ^= 1
+		}
+
+		t.z[i][w] ^= x
+	}
+
+	return t
+}
+
+// X applies the Pauli X gate to the qubit at idx.
+func (t *Tableau) X(idx int) *Tableau {
+	w, m := idx/64, uint64(1)<<(idx%64)
+	for i := range 2 * t.n {
+		if t.z[i][w]&m != 0 {
+			t.r[i] ^= 1
+		}
+	}
+
+	return t
+}
+
+// Y applies the Pauli Y gate to the qubit at idx.
+func (t *Tableau) Y(idx int) *Tableau {
+	w, m := idx/64, uint64(1)<<(idx%64)
+	for i := range 2 * t.n {
+		if (t.x[i][w]^t.z[i][w])&m != 0 {
+			t.r[i] ^= 1
+		}
+	}
+
+	return t
+}
+
+// Z applies the Pauli Z gate to the qubit at idx.
+func (t *Tableau) Z(idx int) *Tableau {
+	w, m := idx/64, uint64(1)<<(idx%64)
+	for i := range 2 * t.n {
+		if t.x[i][w]&m != 0 {
+			t.r[i] ^= 1
+		}
+	}
+
+	return t
+}
+
+// CX applies the controlled-NOT gate.
+func (t *Tableau) CX(control, target int) *Tableau {
+	cw, cm := control/64, uint64(1)<<(control%64)
+	tw, tm := target/64, uint64(1)<<(target%64)
+	for i := range 2 * t.n {
+		xc, zc := t.x[i][cw]&cm != 0, t.z[i][cw]&cm != 0
+		xt, zt := t.x[i][tw]&tm != 0, t.z[i][tw]&tm != 0
+		if xc && zt && xt == zc {
+			t.r[i] ^= 1
+		}
+
+		if xc {
+			t.x[i][tw] ^= tm
+		}
+
+		if zt {
+			t.z[i][cw] ^= cm
+		}
+	}
+
+	return t
+}
+
+// CZ applies the controlled-Z gate.
+func (t *Tableau) CZ(control, target int) *Tableau {
+	return t.H(target).CX(control, target).H(target)
+}
+
+// Swap swaps the qubits at i and j.
+func (t *Tableau) Swap(i, j int) *Tableau {
+	if i == j {
+		return t
+	}
+
+	return t.CX(i, j).CX(j, i).CX(i, j)
+}
+
+// Measure returns the result of measuring the qubit at idx in the computational basis.
+func (t *Tableau) Measure(idx int) int {
+	n := t.n
+	w, m := idx/64, uint64(1)<<(idx%64)
+
+	p := -1
+	for i := n; i < 2*n; i++ {
+		if t.x[i][w]&m != 0 {
+			p = i
+			break
+		}
+	}
+
+	if p < 0 {
+		// the outcome is deterministic.
+		clear(t.x[2*n])
+		clear(t.z[2*n])
+		t.r[2*n] = 0
+		for i := range n {
+			if t.x[i][w]&m != 0 {
+				t.rowsum(2*n, i+n)
+			}
+		}
+
+		return int(t.r[2*n])
+	}
+
+	// the outcome is random.
+	for i := range 2 * n {
+		if i != p && t.x[i][w]&m != 0 {
+			t.rowsum(i, p)
+		}
+	}
+
+	copy(t.x[p-n], t.x[p])
+	copy(t.z[p-n], t.z[p])
+	t.r[p-n] = t.r[p]
+
+	clear(t.x[p])
+	clear(t.z[p])
+	t.z[p][w] = m
+
+	t.r[p] = 0
+	if t.rand() >= 0.5 {
+		t.r[p] = 1
+	}
+
+	return int(t.r[p])
+}
+
+// Reset sets the qubit at idx to the zero state.
+func (t *Tableau) Reset(idx int) *Tableau {
+	if t.Measure(idx) == 1 {
+		t.X(idx)
+	}
+
+	return t
+}
+
+// IsDeterministic returns true if measuring the qubit at idx gives a deterministic outcome.
+func (t *Tableau) IsDeterministic(idx int) bool {
+	w, m := idx/64, uint64(1)<<(idx%64)
+	for i := t.n; i < 2*t.n; i++ {
+		if t.x[i][w]&m != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rowsum sets the row h to the product of the rows h and i.
+func (t *Tableau) rowsum(h, i int) {
+	// sum is the exponent of i in the phase of the product.
+	sum := 2*int(t.r[h]) + 2*int(t.r[i])
+	for k := range t.x[h] {
+		x1, z1 := t.x[i][k], t.z[i][k]
+		x2, z2 := t.x[h][k], t.z[h][k]
+
+		// the function g of Aaronson and Gottesman counted bitwise.
+		y, xo, zo := x1&z1, x1&^z1, z1&^x1
+		plus := y&z2&^x2 | xo&z2&x2 | zo&x2&^z2
+		minus := y&x2&^z2 | xo&z2&^x2 | zo&x2&z2
+		sum += bits.OnesCount64(plus) - bits.OnesCount64(minus)
+
+		t.x[h][k] ^= x1
+		t.z[h][k] ^= z1
+	}
+
+	t.r[h] = 0
+	if ((sum%4)+4)%4 == 2 {
+		t.r[h] = 1
+	}
+}
+
+// Stabilizers returns the stabilizer generators of the state, such as "+XX" and "-ZZ".
+// The i-th character is the Pauli operator acting on the qubit at i.
+func (t *Tableau) Stabilizers() []string {
+	out := make([]string, t.n)
+	for i := range t.n {
+		out[i] = t.pauli(t.n + i)
+	}
+
+	return out
+}
+
+// Destabilizers returns the destabilizer generators of the state.
+func (t *Tableau) Destabilizers() []string {
+	out := make([]string, t.n)
+	for i := range t.n {
+		out[i] = t.pauli(i)
+	}
+
+	return out
+}
+
+func (t *Tableau) pauli(row int) string {
+	var sb strings.Builder
+	sb.WriteByte("+-"[t.r[row]])
+
+	for j := range t.n {
+		w, m := j/64, uint64(1)<<(j%64)
+		switch x, z := t.x[row][w]&m != 0, t.z[row][w]&m != 0; {
+		case x && z:
+			sb.WriteByte('Y')
+		case x:
+			sb.WriteByte('X')
+		case z:
+			sb.WriteByte('Z')
+		default:
+			sb.WriteByte('I')
+		}
+	}
+
+	return sb.String()
+}
+
+// Clone returns a copy of t.
+func (t *Tableau) Clone() *Tableau {
+	x, z := make([][]uint64, len(t.x)), make([][]uint64, len(t.z))
+	for i := range t.x {
+		x[i] = append([]uint64{}, t.x[i]...)
+		z[i] = append([]uint64{}, t.z[i]...)
+	}
+
+	return &Tableau{
+		n:    t.n,
+		x:    x,
+		z:    z,
+		r:    append([]uint8{}, t.r...),
+		rand: t.rand,
+	}
+}
+
+// String returns the stabilizer generators of the state separated by newlines.
+func (t *Tableau) String() string {
+	return strings.Join(t.Stabilizers(), "\n")
+}
+
+func words(n int) int {
+	return (n + 63) / 64
+}
@@ -0,0 +1,175 @@
+package stabilizer_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q/math/epsilon"
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/math/vector"
+	"github.com/itsubaki/q/quantum/observable"
+	"github.com/itsubaki/q/quantum/qubit"
+	"github.com/itsubaki/q/quantum/stabilizer"
+)
+
+func ExampleNew() {
+	t := stabilizer.New(2)
+	fmt.Println(t.Stabilizers())
+	fmt.Println(t.Destabilizers())
+
+	// Output:
+	// [+ZI +IZ]
+	// [+XI +IX]
+}
+
+func ExampleTableau_CX() {
+	t := stabilizer.New(2)
+	t.H(0).CX(0, 1)
+	fmt.Println(t)
+
+	// Output:
+	// +XX
+	// +ZZ
+}
+
+func ExampleTableau_Measure() {
+	t := stabilizer.New(1000)
+	t.SetRand(rand.Const())
+
+	t.H(0)
+	for i := 1; i < t.NumQubits(); i++ {
+		t.CX(0, i)
+	}
+
+	m := t.Measure(0)
+	fmt.Println(t.IsDeterministic(999))
+	fmt.Println(t.Measure(999) == m)
+	fmt.Println(t.Measure(500) == m)
+
+	// Output:
+	// true
+	// true
+	// true
+}
+
+func ExampleTableau_Add() {
+	t := stabilizer.New(1)
+	t.X(0)
+	t.Add(1)
+
+	fmt.Println(t.Stabilizers())
+	fmt.Println(t.Measure(0), t.Measure(1))
+
+	// Output:
+	// [-ZI +IZ]
+	// 1 0
+}
+
+func ExampleTableau_Reset() {
+	t := stabilizer.New(1)
+	t.SetRand(rand.Const())
+	t.H(0).Reset(0)
+
+	fmt.Println(t)
+
+	// Output:
+	// +Z
+}
+
+func TestTableau(t *testing.T) {
+	// compare with the state vector simulation of random Clifford circuits.
+	r := rand.Const(1)
+	for range 100 {
+		n := 4
+		tab, qb := stabilizer.New(n), qubit.Zeros(n)
+		for range 30 {
+			i, j := int(r()*float64(n)), int(r()*float64(n))
+			switch int(r() * 8) {
+			case 0:
+				tab.H(i)
+				qb.H(i)
+			case 1:
+				tab.S(i)
+				qb.S(i)
+			case 2:
+				tab.X(i)
+				qb.X(i)
+			case 3:
+				tab.Y(i)
+				qb.Y(i)
+			case 4:
+				tab.Z(i)
+				qb.Z(i)
+			case 5:
+				if i != j {
+					tab.CX(i, j)
+					qb.CX(i, j)
+				}
+			case 6:
+				if i != j {
+					tab.CZ(i, j)
+					qb.CZ(i, j)
+				}
+			case 7:
+				tab.Swap(i, j)
+				qb.Swap(i, j)
+			}
+		}
+
+		v := vector.New(qb.Amplitude()...)
+		for _, s := range tab.Stabilizers() {
+			want := v.Clone()
+			if s[0] == '-' {
+				want = want.Mul(-1)
+			}
+
+			if got := v.Apply(observable.Pauli(s[1:])); !got.Equal(want) {
+				t.Fatalf("%v is not a stabilizer", s)
+			}
+		}
+
+		for i := range n {
+			var p1 float64
+			for k, p := range qb.Probability() {
+				if k&(1<<(n-1-i)) != 0 {
+					p1 += p
+				}
+			}
+
+			det := epsilon.IsZeroF64(p1) || epsilon.IsOneF64(p1)
+			if tab.IsDeterministic(i) != det {
+				t.Fatalf("qubit %d: deterministic=%v, p1=%v", i, tab.IsDeterministic(i), p1)
+			}
+
+			if det && tab.Clone().Measure(i) != int(p1+0.5) {
+				t.Fatalf("qubit %d: p1=%v", i, p1)
+			}
+		}
+	}
+}
+
+func TestTableau_Measure(t *testing.T) {
+	cases := []struct {
+		rand float64
+		want int
+	}{
+		{0.1, 0},
+		{0.9, 1},
+	}
+
+	for _, c := range cases {
+		tab := stabilizer.New(3)
+		tab.SetRand(func() float64 { return c.rand })
+		tab.H(0).CX(0, 1).CX(1, 2)
+
+		for i := range 3 {
+			if got := tab.Measure(i); got != c.want {
+				t.Errorf("got=%v, want=%v", got, c.want)
+			}
+		}
+
+		if tab.Rand()() != c.rand {
+			t.Fail()
+		}
+	}
+}
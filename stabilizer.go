@@ -0,0 +1,280 @@
+package q
+
+import (
+	"github.com/itsubaki/q/quantum/qubit"
+	"github.com/itsubaki/q/quantum/stabilizer"
+)
+
+// Stabilizer is a quantum computing simulator for Clifford circuits.
+// It has the same method names as Q and simulates thousands of qubits.
+type Stabilizer struct {
+	t   *stabilizer.Tableau
+	rec *Circuit
+}
+
+// NewStabilizer returns a new stabilizer simulator.
+func NewStabilizer() *Stabilizer {
+	return &Stabilizer{
+		t: stabilizer.New(0),
+	}
+}
+
+// SetRand sets the random number generator.
+func (s *Stabilizer) SetRand(rand func() float64) {
+	s.t.SetRand(rand)
+}
+
+// Record starts recording the operations applied to s into c.
+// If c is nil, it stops recording.
+func (s *Stabilizer) Record(c *Circuit) *Stabilizer {
+	s.rec = c
+	return s
+}
+
+// Zero returns a qubit in the zero state.
+func (s *Stabilizer) Zero() Qubit {
+	s.t.Add(1)
+
+	qb := Qubit(s.NumQubits() - 1)
+	s.record(Op{Type: OpNew, Target: []Qubit{qb}, State: []complex128{1, 0}})
+	return qb
+}
+
+// One returns a qubit in the one state.
+func (s *Stabilizer) One() Qubit {
+	s.t.Add(1)
+
+	qb := Qubit(s.NumQubits() - 1)
+	s.t.X(qb.Index())
+	s.record(Op{Type: OpNew, Target: []Qubit{qb}, State: []complex128{0, 1}})
+	return qb
+}
+
+// Zeros returns n qubits in the zero state.
+func (s *Stabilizer) Zeros(n int) []Qubit {
+	qb := make([]Qubit, n)
+	for i := range n {
+		qb[i] = s.Zero()
+	}
+
+	return qb
+}
+
+// Ones returns n qubits in the one state.
+func (s *Stabilizer) Ones(n int) []Qubit {
+	qb := make([]Qubit, n)
+	for i := range n {
+		qb[i] = s.One()
+	}
+
+	return qb
+}
+
+// NumQubits returns the number of qubits.
+func (s *Stabilizer) NumQubits() int {
+	return s.t.NumQubits()
+}
+
+// Reset sets the given qubits to the zero state.
+func (s *Stabilizer) Reset(qb ...Qubit) {
+	for i := range qb {
+		s.record(Op{Type: OpReset, Target: []Qubit{qb[i]}})
+		s.t.Reset(qb[i].Index())
+	}
+}
+
+// I applies the I gate.
+func (s *Stabilizer) I(qb ...Qubit) *Stabilizer {
+	for i := range qb {
+		s.record(Op{Type: OpI, Target: []Qubit{qb[i]}})
+	}
+
+	return s
+}
+
+// X applies the X gate.
+func (s *Stabilizer) X(qb ...Qubit) *Stabilizer {
+	return s.apply(OpX, s.t.X, qb)
+}
+
+// Y applies the Y gate.
+func (s *Stabilizer) Y(qb ...Qubit) *Stabilizer {
+	return s.apply(OpY, s.t.Y, qb)
+}
+
+// Z applies the Z gate.
+func (s *Stabilizer) Z(qb ...Qubit) *Stabilizer {
+	return s.apply(OpZ, s.t.Z, qb)
+}
+
+// H applies the H gate.
+func (s *Stabilizer) H(qb ...Qubit) *Stabilizer {
+	return s.apply(OpH, s.t.H, qb)
+}
+
+// S applies the S gate.
+func (s *Stabilizer) S(qb ...Qubit) *Stabilizer {
+	return s.apply(OpS, s.t.S, qb)
+}
+
+// CX applies the CNOT gate.
+func (s *Stabilizer) CX(control, target Qubit) *Stabilizer {
+	return s.ControlledX([]Qubit{control}, []Qubit{target})
+}
+
+// CNOT applies the CNOT gate.
+func (s *Stabilizer) CNOT(control, target Qubit) *Stabilizer {
+	return s.ControlledX([]Qubit{control}, []Qubit{target})
+}
+
+// CZ applies the controlled-Z gate.
+func (s *Stabilizer) CZ(control, target Qubit) *Stabilizer {
+	return s.ControlledZ([]Qubit{control}, []Qubit{target})
+}
+
+// ControlledX applies the CNOT gate.
+// It panics unless there is exactly one control qubit, since the gate is not a Clifford gate otherwise.
+func (s *Stabilizer) ControlledX(control, target []Qubit) *Stabilizer {
+	return s.controlled(OpControlledX, s.t.CX, control, target)
+}
+
+// ControlledNot applies the CNOT gate.
+func (s *Stabilizer) ControlledNot(control, target []Qubit) *Stabilizer {
+	return s.ControlledX(control, target)
+}
+
+// ControlledZ applies the controlled-Z gate.
+// It panics unless there is exactly one control qubit.
+func (s *Stabilizer) ControlledZ(control, target []Qubit) *Stabilizer {
+	return s.controlled(OpControlledZ, s.t.CZ, control, target)
+}
+
+// CondX applies the X gate if condition is true.
+func (s *Stabilizer) CondX(condition bool, qb ...Qubit) *Stabilizer {
+	if condition {
+		return s.X(qb...)
+	}
+
+	return s
+}
+
+// CondZ applies the Z gate if condition is true.
+func (s *Stabilizer) CondZ(condition bool, qb ...Qubit) *Stabilizer {
+	if condition {
+		return s.Z(qb...)
+	}
+
+	return s
+}
+
+// Swap applies the swap gate.
+func (s *Stabilizer) Swap(qb0, qb1 Qubit) *Stabilizer {
+	s.record(Op{Type: OpSwap, Target: []Qubit{qb0, qb1}})
+	s.t.Swap(qb0.Index(), qb1.Index())
+	return s
+}
+
+// M returns the measured state of the given qubits.
+func (s *Stabilizer) M(qb ...Qubit) *qubit.Qubit {
+	return s.Measure(qb...)
+}
+
+// Measure returns the measured state of the given qubits.
+// The returned state has 2^len(qb) amplitudes. Use MeasureBits for many qubits.
+func (s *Stabilizer) Measure(qb ...Qubit) *qubit.Qubit {
+	bits := s.MeasureBits(qb...)
+
+	m := make([]*qubit.Qubit, len(bits))
+	for i := range bits {
+		m[i] = qubit.Zero()
+		if bits[i] == 1 {
+			m[i] = qubit.One()
+		}
+	}
+
+	return qubit.TensorProduct(m...)
+}
+
+// MeasureBits returns the measured bits of the given qubits.
+// If no qubits are given, it measures all qubits.
+func (s *Stabilizer) MeasureBits(qb ...Qubit) []int {
+	if len(qb) < 1 {
+		qb = make([]Qubit, s.NumQubits())
+		for i := range qb {
+			qb[i] = Qubit(i)
+		}
+	}
+
+	bits := make([]int, len(qb))
+	for i := range qb {
+		s.recordMeasure(qb[i])
+		bits[i] = s.t.Measure(qb[i].Index())
+	}
+
+	return bits
+}
+
+// Stabilizers returns the stabilizer generators of the state, such as "+XX" and "+ZZ".
+func (s *Stabilizer) Stabilizers() []string {
+	return s.t.Stabilizers()
+}
+
+// Clone returns a copy of s.
+func (s *Stabilizer) Clone() *Stabilizer {
+	return &Stabilizer{
+		t: s.t.Clone(),
+	}
+}
+
+// Tableau returns the internal tableau.
+func (s *Stabilizer) Tableau() *stabilizer.Tableau {
+	return s.t
+}
+
+// String returns the string representation of s.
+func (s *Stabilizer) String() string {
+	return s.t.String()
+}
+
+func (s *Stabilizer) apply(t OpType, g func(int) *stabilizer.Tableau, qb []Qubit) *Stabilizer {
+	for i := range qb {
+		s.record(Op{Type: t, Target: []Qubit{qb[i]}})
+		g(qb[i].Index())
+	}
+
+	return s
+}
+
+func (s *Stabilizer) controlled(t OpType, g func(int, int) *stabilizer.Tableau, control, target []Qubit) *Stabilizer {
+	if len(control) != 1 {
+		panic("stabilizer: the number of control qubits must be one")
+	}
+
+	for i := range target {
+		s.record(Op{Type: t, Control: control, Target: []Qubit{target[i]}})
+		g(control[0].Index(), target[i].Index())
+	}
+
+	return s
+}
+
+func (s *Stabilizer) record(op Op) {
+	if s.rec == nil {
+		return
+	}
+
+	op.Control = append([]Qubit(nil), op.Control...)
+	s.rec.Add(op)
+}
+
+func (s *Stabilizer) recordMeasure(qb Qubit) {
+	if s.rec == nil {
+		return
+	}
+
+	s.rec.Add(Op{
+		Type:   OpMeasure,
+		Target: []Qubit{qb},
+		Clbit:  []int{s.rec.NumClbits},
+	})
+}
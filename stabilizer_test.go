@@ -0,0 +1,111 @@
+package q_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/itsubaki/q"
+	"github.com/itsubaki/q/math/rand"
+)
+
+func ExampleStabilizer() {
+	qsim := q.NewStabilizer()
+	qsim.SetRand(rand.Const())
+
+	qb := qsim.Zeros(1000)
+	qsim.H(qb[0])
+	for i := 1; i < len(qb); i++ {
+		qsim.CNOT(qb[0], qb[i])
+	}
+
+	m := qsim.MeasureBits()
+
+	var sum int
+	for _, b := range m {
+		sum += b
+	}
+
+	fmt.Println(sum == 0 || sum == len(qb))
+
+	// Output:
+	// true
+}
+
+func ExampleStabilizer_teleportation() {
+	qsim := q.NewStabilizer()
+	qsim.SetRand(rand.Const())
+
+	phi := qsim.One()
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+
+	qsim.H(q0).CNOT(q0, q1)
+	qsim.CNOT(phi, q0).H(phi)
+
+	mz := qsim.Measure(phi)
+	mx := qsim.Measure(q0)
+
+	qsim.CondX(mx.IsOne(), q1)
+	qsim.CondZ(mz.IsOne(), q1)
+
+	fmt.Println(qsim.Measure(q1))
+
+	// Output:
+	// [(0+0i) (1+0i)]
+}
+
+func ExampleStabilizer_Stabilizers() {
+	qsim := q.NewStabilizer()
+
+	q0 := qsim.Zero()
+	q1 := qsim.One()
+	qsim.H(q0).CZ(q0, q1).S(q1)
+
+	fmt.Println(qsim.Stabilizers())
+
+	// Output:
+	// [+XZ -IZ]
+}
+
+func TestStabilizer(t *testing.T) {
+	c := q.NewCircuit()
+
+	s := q.NewStabilizer().Record(c)
+	qb := s.Zeros(3)
+	s.H(qb[0]).CX(qb[0], qb[1]).ControlledZ(qb[1:2], qb[2:]).Swap(qb[0], qb[2])
+	s.X(qb[0]).Y(qb[1]).Z(qb[2]).I(qb[0]).S(qb[1])
+	s.ControlledNot(qb[:1], qb[1:]).H(qb[1])
+
+	if len(c.Ops) != 15 {
+		t.Errorf("got=%v", c)
+	}
+
+	// the recorded circuit replayed by Q has the same deterministic outcomes.
+	qsim := q.New()
+	qsim.Run(c)
+
+	for i := range qb {
+		if !s.Tableau().IsDeterministic(i) {
+			continue
+		}
+
+		want := s.Clone().Measure(qb[i]).IsOne()
+		for range 10 {
+			if got := qsim.Clone().Measure(qb[i]).IsOne(); got != want {
+				t.Errorf("qubit %d: got=%v, want=%v", i, got, want)
+			}
+		}
+	}
+}
+
+func TestStabilizer_panic(t *testing.T) {
+	defer func() {
+		if rec := recover(); rec == nil {
+			t.Fail()
+		}
+	}()
+
+	s := q.NewStabilizer()
+	qb := s.Zeros(3)
+	s.ControlledX(qb[:2], qb[2:])
+}
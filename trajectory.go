@@ -0,0 +1,202 @@
+package q
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/itsubaki/q/math/rand"
+	"github.com/itsubaki/q/quantum/noise"
+	"github.com/itsubaki/q/quantum/qubit"
+)
+
+// Trajectory is a noisy quantum computing simulator that samples quantum trajectories.
+// Each trajectory runs a circuit on a state vector and applies one Kraus operator
+// of the noise channel for each operation, chosen with the Born probability.
+// The average over the trajectories converges to the density matrix simulation.
+type Trajectory struct {
+	noise   *noise.Model
+	seed    uint64
+	workers int
+}
+
+// NewTrajectory returns a new trajectory simulator with the noise model.
+// The idle noise is applied to the other qubits once per recorded operation as noise.Model.SetIdle.
+// If m is nil, no noise is applied.
+func NewTrajectory(m *noise.Model) *Trajectory {
+	return &Trajectory{
+		noise: m,
+	}
+}
+
+// SetSeed sets the seed of the trajectories.
+// The i-th trajectory uses the random number generator rand.Const(seed, i).
+func (t *Trajectory) SetSeed(seed uint64) {
+	t.seed = seed
+}
+
+// SetWorkers sets the number of goroutines that run the trajectories.
+// If n is less than 2, the trajectories run serially.
+func (t *Trajectory) SetWorkers(n int) {
+	t.workers = n
+}
+
+// Run runs the i-th trajectory of c and returns the simulator and the classical bits.
+// The classical bits have the readout errors of the noise model.
+func (t *Trajectory) Run(c *Circuit, i int) (*Q, []int) {
+	qsim := New()
+	qsim.SetRand(rand.Const(t.seed, uint64(i)))
+
+	s := &trajectory{Q: qsim, noise: t.noise}
+	bits := run(s, c)
+	return qsim, bits
+}
+
+// Average returns the average of f over n trajectories of c.
+// f is called with the final state and the classical bits of each trajectory.
+func (t *Trajectory) Average(c *Circuit, n int, f func(qsim *Q, bits []int) float64) float64 {
+	v := make([]float64, n)
+	t.parallel(n, func(i int) {
+		v[i] = f(t.Run(c, i))
+	})
+
+	var sum float64
+	for i := range v {
+		sum += v[i]
+	}
+
+	return sum / float64(n)
+}
+
+// ExpectPauli returns the average of the expectation value of the Pauli string over n trajectories of c.
+// It returns an error if s is not a valid Pauli string for the qubits.
+func (t *Trajectory) ExpectPauli(c *Circuit, n int, s string, qb ...Qubit) (float64, error) {
+	var mu sync.Mutex
+	var err error
+	v := t.Average(c, n, func(qsim *Q, _ []int) float64 {
+		v, e := qsim.ExpectPauli(s, qb...)
+		if e != nil {
+			mu.Lock()
+			err = e
+			mu.Unlock()
+		}
+
+		return v
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return v, nil
+}
+
+// Counts returns the counts of the classical bits over n trajectories of c.
+// The i-th character of the binary strings is the i-th classical bit.
+func (t *Trajectory) Counts(c *Circuit, n int) qubit.Counts {
+	s := make([]string, n)
+	t.parallel(n, func(i int) {
+		_, bits := t.Run(c, i)
+
+		var sb strings.Builder
+		for _, b := range bits {
+			sb.WriteString(strconv.Itoa(b))
+		}
+
+		s[i] = sb.String()
+	})
+
+	counts := make(qubit.Counts)
+	for i := range s {
+		counts[s[i]]++
+	}
+
+	return counts
+}
+
+// parallel calls f for the trajectories in [0, n) using the workers.
+func (t *Trajectory) parallel(n int, f func(i int)) {
+	w := min(t.workers, n)
+	if w < 2 {
+		for i := range n {
+			f(i)
+		}
+
+		return
+	}
+
+	var wg sync.WaitGroup
+	for k := range w {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := k; i < n; i += w {
+				f(i)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// trajectory is a simulator that applies the noise of the model after each operation.
+type trajectory struct {
+	*Q
+	noise *noise.Model
+}
+
+// apply applies op and samples the noise of the operation.
+func (s *trajectory) apply(op Op, bits []int) {
+	s.Q.apply(op, bits)
+	if s.noise == nil {
+		return
+	}
+
+	switch op.Type {
+	case OpBarrier:
+		return
+	case OpMeasure:
+		// the qubits without classical bits are not read.
+		n := min(len(op.Target), len(op.Clbit))
+		measured := make([]int, n)
+		for i := range n {
+			measured[i] = bits[op.Clbit[i]]
+		}
+
+		for i, b := range s.noise.Read(measured, Index(op.Target[:n]...), s.qb.Rand()) {
+			bits[op.Clbit[i]] = b
+		}
+
+		return
+	}
+
+	qb := op.Qubits()
+	if op.Type == OpApply {
+		qb = make([]Qubit, s.NumQubits())
+		for i := range qb {
+			qb[i] = Qubit(i)
+		}
+	}
+
+	if ch := s.noise.Channel(string(op.Type), Index(qb...)...); ch != nil {
+		s.qb.ApplyKraus(ch.Kraus, Index(qb...)...)
+	}
+
+	idle := s.noise.Idle()
+	if idle == nil {
+		return
+	}
+
+	busy := make(map[int]bool)
+	for _, q := range qb {
+		busy[q.Index()] = true
+	}
+
+	for i := range s.NumQubits() {
+		if busy[i] {
+			continue
+		}
+
+		s.qb.ApplyKraus(idle.Kraus, i)
+	}
+}
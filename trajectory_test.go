@@ -0,0 +1,138 @@
+package q_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/itsubaki/q"
+	"github.com/itsubaki/q/math/number"
+	"github.com/itsubaki/q/quantum/channel"
+	"github.com/itsubaki/q/quantum/noise"
+	"github.com/itsubaki/q/quantum/readout"
+)
+
+func ExampleTrajectory() {
+	m := noise.New().Add(channel.Depolarizing(0.3, 0), "H")
+
+	c := q.NewCircuit()
+	qsim := q.New()
+	qsim.Record(c)
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	qsim.H(q0).CNOT(q0, q1)
+
+	dsim := q.NewDensity()
+	dsim.SetNoise(m)
+	dsim.Run(c)
+
+	tsim := q.NewTrajectory(m)
+	tsim.SetSeed(1)
+	got := number.Must(tsim.ExpectPauli(c, 1000, "XX"))
+
+	fmt.Printf("%.4f\n", number.Must(dsim.ExpectPauli("XX")))
+	fmt.Println(math.Abs(got-number.Must(dsim.ExpectPauli("XX"))) < 0.1)
+
+	// Output:
+	// 0.6000
+	// true
+}
+
+func ExampleTrajectory_Counts() {
+	m := noise.New().
+		SetReadout(readout.New(
+			readout.Assignment(0, 1),
+			readout.Assignment(1, 0),
+		))
+
+	c := q.NewCircuit()
+	qsim := q.New()
+	qsim.Record(c)
+	q0 := qsim.Zero()
+	q1 := qsim.Zero()
+	qsim.X(q0)
+	qsim.Measure(q0, q1)
+
+	tsim := q.NewTrajectory(m)
+	fmt.Println(tsim.Counts(c, 100))
+
+	// Output:
+	// map[01:100]
+}
+
+func TestTrajectory(t *testing.T) {
+	cases := []struct {
+		model *noise.Model
+		s     string
+	}{
+		{nil, "ZIZ"},
+		{noise.New().Add(channel.Depolarizing(0.2, 1), "ControlledX"), "ZZI"},
+		{noise.New().Add(channel.AmplitudeDamping(0.4, 0), "H", "X"), "ZII"},
+		{noise.New().AddQubits(channel.BitFlip(0.3, 0), []int{2}, "X"), "ZIZ"},
+		{noise.New().SetIdle(channel.PhaseDamping(0.5, 0)), "XXX"},
+	}
+
+	c := q.NewCircuit()
+	qsim := q.New()
+	qsim.Record(c)
+	qb := qsim.Zeros(3)
+	qsim.H(qb[0]).CNOT(qb[0], qb[1]).CNOT(qb[1], qb[2]).X(qb[2])
+
+	for _, cs := range cases {
+		dsim := q.NewDensity()
+		dsim.SetNoise(cs.model)
+		dsim.Run(c)
+		want := number.Must(dsim.ExpectPauli(cs.s))
+
+		tsim := q.NewTrajectory(cs.model)
+		tsim.SetSeed(1)
+		if got := number.Must(tsim.ExpectPauli(c, 2000, cs.s)); math.Abs(got-want) > 0.05 {
+			t.Errorf("%v: got=%v, want=%v", cs.s, got, want)
+		}
+	}
+}
+
+func TestTrajectory_SetWorkers(t *testing.T) {
+	m := noise.New().
+		Add(channel.Depolarizing(0.1, 0), "H").
+		Add(channel.AmplitudeDamping(0.2, 1), "ControlledX").
+		SetIdle(channel.PhaseDamping(0.1, 0)).
+		SetReadout(readout.New(
+			readout.Assignment(0, 0),
+			readout.Assignment(0.1, 0.2),
+		))
+
+	c := q.NewCircuit()
+	qsim := q.New()
+	qsim.Record(c)
+	qb := qsim.Zeros(3)
+	qsim.H(qb[0]).CNOT(qb[0], qb[1]).CNOT(qb[1], qb[2])
+	qsim.Measure(qb...)
+
+	serial := q.NewTrajectory(m)
+	serial.SetSeed(3)
+	want := serial.Counts(c, 100)
+
+	cases := []struct {
+		workers int
+	}{
+		{1}, {2}, {4}, {8},
+	}
+
+	for _, cs := range cases {
+		tsim := q.NewTrajectory(m)
+		tsim.SetSeed(3)
+		tsim.SetWorkers(cs.workers)
+
+		got := tsim.Counts(c, 100)
+		if len(got) != len(want) {
+			t.Fatalf("got=%v, want=%v", got, want)
+		}
+
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%v: got=%v, want=%v", k, got[k], v)
+			}
+		}
+	}
+}